@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jwhitt3r/m-check/internal/report"
+)
+
+var baselineUsage = `
+Baseline:
+	-baseline File of known broken links, saved by -update-baseline or as json results. Only links
+	          broken since are reported as broken, and links of the baseline that are fixed are listed.
+	-update-baseline Record every link that is currently broken within the baseline file.
+`
+
+// baselineOptions holds the flags shared by the commands that check links.
+type baselineOptions struct {
+	path   string
+	update bool
+}
+
+// addBaselineFlags registers the flags used to compare the results with a
+// baseline with the FlagSet.
+func addBaselineFlags(fs *flag.FlagSet) *baselineOptions {
+	opts := baselineOptions{}
+	fs.StringVar(&opts.path, "baseline", "", "File of known broken links.")
+	fs.BoolVar(&opts.update, "update-baseline", false, "Record every link that is currently broken within the baseline file.")
+	return &opts
+}
+
+// apply sets aside the broken links known from the baseline, and then,
+// when the baseline is being updated, records every link that is broken
+// now as known.
+func (opts *baselineOptions) apply(rep *report.Report) error {
+	if opts.path == "" {
+		return nil
+	}
+
+	baseline, err := readReport(opts.path)
+	switch {
+	case err == nil:
+		rep.ApplyBaseline(baseline.Failures())
+	case !(os.IsNotExist(err) && opts.update):
+		return err
+	}
+	if !opts.update {
+		return nil
+	}
+
+	rep.ApplyBaseline(rep.Failures())
+	if status := writeOutput(opts.path, func(w io.Writer) error { return report.New(rep.Known).Write(w, "json") }); status != 0 {
+		return fmt.Errorf("writing baseline %s", opts.path)
+	}
+	fmt.Fprintf(os.Stderr, "[+] Recorded %d Known Broken Links In %s\n", len(rep.Known), opts.path)
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/cache"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+var cacheUsage = `
+Result Cache:
+	-cache File to remember the outcome of each URL in between runs, e.g., ~/.cache/m-check.json.
+	-cache-ttl How long the outcome of a working link is reused for. By default 24h.
+	-cache-failure-ttl How long the outcome of a broken link is reused for. By default 1h.
+`
+
+// cacheOptions holds the flags shared by the commands that check links.
+type cacheOptions struct {
+	path       string
+	ttl        time.Duration
+	failureTTL time.Duration
+}
+
+// addCacheFlags registers the flags used to cache outcomes on disk with the
+// FlagSet.
+func addCacheFlags(fs *flag.FlagSet) *cacheOptions {
+	opts := cacheOptions{}
+	fs.StringVar(&opts.path, "cache", "", "File to remember the outcome of each URL in between runs.")
+	fs.DurationVar(&opts.ttl, "cache-ttl", cache.DefaultSuccessTTL, "How long the outcome of a working link is reused for.")
+	fs.DurationVar(&opts.failureTTL, "cache-failure-ttl", cache.DefaultFailureTTL, "How long the outcome of a broken link is reused for.")
+	return &opts
+}
+
+// use opens the cache and sets it on the checker, returning nil when no
+// cache has been asked for.
+func (opts *cacheOptions) use(checker *urlcheck.URLChecker) (*cache.Cache, error) {
+	if opts.path == "" {
+		return nil, nil
+	}
+	c, err := cache.Open(opts.path)
+	if err != nil {
+		return nil, err
+	}
+	c.SuccessTTL, c.FailureTTL = opts.ttl, opts.failureTTL
+	checker.UseCache(c)
+	return c, nil
+}
+
+// save writes the cache back to disk. A cache that cannot be saved only
+// costs the next run its speed, so the failure is logged rather than
+// failing the run.
+func (opts *cacheOptions) save(c *cache.Cache) {
+	if c == nil {
+		return
+	}
+	if err := c.Save(); err != nil {
+		log.Printf("Failed to save the cache: %v\n", err)
+	}
+}
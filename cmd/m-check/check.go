@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mcheck "github.com/jwhitt3r/m-check"
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/report"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+var checkUsage = `Usage: m-check check [options...] [directory]
+
+Recursively discovers the markdown files within the directory, which defaults
+to the current directory, and checks every link found within them. Files
+excluded by a .gitignore file are skipped. Alternatively, a list of links
+saved by the extract command can be checked with -links.
+
+Optional:
+	-i Glob of files to include, may be repeated. By default "*.md" and "*.markdown".
+	-e Glob of files or directories to exclude, may be repeated.
+	-links File of links saved by the extract command, or "-" for the standard input.
+	-f Format of the results, one of ` + strings.Join(report.Formats, ", ") + `. By default text.
+	-out File to write the results to, by default the standard output.
+` + timeoutUsage + streamUsage + progressUsage + `
+Globs without a "/" match the file name at any depth, otherwise they match the
+path relative to the directory, where "**" matches any number of directories.
+` + changedUsage + manifestUsage + cacheUsage + baselineUsage + fixUsage + `
+When -manifest is given without -cache, the outcome of each URL is cached beside
+the manifest, so that only new links, or links whose outcome has expired, are
+checked again while the report still covers every link.
+
+Output:
+	Each link and its status code is written out, and the command exits with a
+	status of 1 when a broken link has been found. Results saved in the json
+	format can be rendered into another format by the report command. When the
+	run is interrupted, or passes its -timeout, the links checked so far are
+	still written out, marked as incomplete.
+
+Examples:
+	Example For Checking A Working Tree: ./m-check check ./
+
+	Example For Checking Only The Docs Directory: ./m-check check -i "docs/**/*.md" -e "docs/archive" ./
+
+	Example For Checking Extracted Links: ./m-check check -links links.json -f json -out results.json
+
+	Example For Checking The Lines Changed On A Branch: ./m-check check -changed main..HEAD -added ./
+
+	Example For Checking A Pull Request: ./m-check check -o jwhitt3r -r m-check -pr 12 ./
+
+	Example For Incrementally Checking A Large Docs Set: ./m-check check -manifest .m-check/manifest.json ./docs
+
+	Example For Reusing Recent Outcomes: ./m-check check -cache ~/.cache/m-check.json ./
+
+	Example For Writing Each Result As Soon As It Is Checked: ./m-check check -stream ./
+
+	Example For Previewing The Fixes To Redirected Links: ./m-check check -fix -dry-run ./docs
+`
+
+// checkCommand checks the links of a local directory or git working tree,
+// or of a list of links saved by the extract command.
+func checkCommand(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	opts := addExtractFlags(fs)
+	linksFile := fs.String("links", "", "File of links saved by the extract command.")
+	format := fs.String("f", "text", "Format of the results.")
+	out := fs.String("out", "", "File to write the results to.")
+	timeout := fs.Duration("timeout", 0, "Longest the whole run may take.")
+	stream := fs.Bool("stream", false, "Write each result as soon as it has been checked.")
+	progressMode := addProgressFlag(fs)
+	cacheOpts := addCacheFlags(fs)
+	baselineOpts := addBaselineFlags(fs)
+	fixOpts := addFixFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, checkUsage)
+	}
+	fs.Parse(args)
+	defer opts.github.saveResponses()
+	if opts.manifest != "" && cacheOpts.path == "" {
+		cacheOpts.path = strings.TrimSuffix(opts.manifest, filepath.Ext(opts.manifest)) + ".cache.json"
+	}
+	if *stream && (*format != "text" || baselineOpts.path != "") {
+		return commandUsage(fs, "Only the text format can be streamed, without a baseline")
+	}
+	if msg := fixOpts.validate(*out); msg != "" {
+		return commandUsage(fs, msg)
+	}
+
+	client := http.Client{Timeout: 5 * time.Second}
+	urlChecker := urlcheck.NewURLCheck(&client)
+	if err := opts.github.validateLinks(urlChecker); err != nil {
+		log.Printf("Failed to connect to GitHub: %v\n", err)
+		return 1
+	}
+	c, err := cacheOpts.use(urlChecker)
+	if err != nil {
+		log.Printf("Failed to open the cache: %v\n", err)
+		return 1
+	}
+
+	p, err := startProgress(*progressMode, *stream && isTerminalOutput(*out))
+	if err != nil {
+		return commandUsage(fs, fmt.Sprintf("The progress mode is not valid: %v", err))
+	}
+
+	var reporters []mcheck.Reporter
+	var streamed io.WriteCloser
+	if *stream {
+		if streamed, err = createOutput(*out); err != nil {
+			log.Printf("Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer streamed.Close()
+		reporters = append(reporters, streamReporter(streamed))
+	}
+
+	ctx, cancel := runContext(*timeout)
+	defer cancel()
+	rep, err := mcheck.Run(ctx, mcheck.Options{
+		Extractor: extractor{opts: opts, root: fs.Arg(0), linksFile: *linksFile, progress: p},
+		Checker:   checker{urlChecker, p},
+		Reporters: reporters,
+	})
+	p.Stop()
+	if rep == nil {
+		log.Printf("Failed to check links: %v\n", err)
+		return 1
+	}
+	if err != nil {
+		log.Printf("Stopped early: %v\n", err)
+	}
+	cacheOpts.save(c)
+	if err := baselineOpts.apply(rep); err != nil {
+		log.Printf("Failed to apply the baseline: %v\n", err)
+		return 1
+	}
+
+	if streamed == nil {
+		if status := writeOutput(*out, func(w io.Writer) error { return rep.Write(w, *format) }); status != 0 {
+			return status
+		}
+	}
+	if err := fixOpts.apply(rep); err != nil {
+		log.Printf("Failed to fix redirected links: %v\n", err)
+		return 1
+	}
+	if len(rep.Broken()) > 0 || len(rep.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readLinks decodes a list of links that has been saved by the extract command.
+func readLinks(name string) ([]markdown.Link, error) {
+	f, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var links []markdown.Link
+	if err := json.NewDecoder(f).Decode(&links); err != nil {
+		return nil, fmt.Errorf("decoding links: %w", err)
+	}
+	return links, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/report"
+)
+
+var diffUsage = `Usage: m-check diff [options...] <old results> <new results>
+
+Compares two results files, saved by the check or scan commands in the json
+format, and lists the links that newly broke, were fixed, changed status or
+started redirecting. Links are matched by their URL and the file they were
+found in, so lines moving within a file are not counted as changes.
+
+Optional:
+	-f Format of the changes, one of ` + strings.Join(report.Formats, ", ") + `. By default text.
+	-out File to write the changes to, by default the standard output.
+
+Output:
+	The command exits with a status of 1 when a link has newly broken.
+
+Examples:
+	Example For A Weekly Summary: ./m-check diff -f markdown -out changes.md last-week.json results.json
+`
+
+// diffCommand lists what changed between two saved results files.
+func diffCommand(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("f", "text", "Format of the changes.")
+	out := fs.String("out", "", "File to write the changes to.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, diffUsage)
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return commandUsage(fs, "Both the old and new results files must be given")
+	}
+
+	old, err := readReport(fs.Arg(0))
+	if err != nil {
+		log.Printf("Failed to read results: %v\n", err)
+		return 1
+	}
+	current, err := readReport(fs.Arg(1))
+	if err != nil {
+		log.Printf("Failed to read results: %v\n", err)
+		return 1
+	}
+
+	d := report.Compare(old, current)
+	if status := writeOutput(*out, func(w io.Writer) error { return d.Write(w, *format) }); status != 0 {
+		return status
+	}
+	if len(d.Broken) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	mcheck "github.com/jwhitt3r/m-check"
+	"github.com/jwhitt3r/m-check/internal/changes"
+	"github.com/jwhitt3r/m-check/internal/manifest"
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+)
+
+var extractUsage = `Usage: m-check extract [options...] [directory]
+
+Recursively discovers the markdown files within the directory, which defaults
+to the current directory, and lists every link found within them as JSON.
+Files excluded by a .gitignore file are skipped.
+
+Optional:
+	-i Glob of files to include, may be repeated. By default "*.md" and "*.markdown".
+	-e Glob of files or directories to exclude, may be repeated.
+	-out File to write the links to, by default the standard output.
+` + changedUsage + manifestUsage + `
+Examples:
+	Example For Listing The Links Of A Working Tree: ./m-check extract -out links.json ./
+
+	Example For Listing The Links Of Fetched Documentation: ./m-check extract ./docs/jwhitt3r/m-check
+`
+
+var changedUsage = `
+Changed Files:
+	-changed Range of revisions, e.g., main..HEAD, to only extract the files changed within.
+	-pr Number of a pull request to only extract the files it changes, requires -o and -r.
+	-o Owner of the repository holding the pull request.
+	-r Repository holding the pull request.
+	-t Your GitHub Personal Token, required for private repositories.
+	-added Only extract the links found on lines that have been added or modified.
+` + enterpriseUsage
+
+var manifestUsage = `
+Incremental Extraction:
+	-manifest File to remember the hash and links of each file in between runs, so that only the
+	          files that have changed are parsed again. It cannot be combined with -changed or -pr.
+`
+
+// extractOptions holds the flags shared by the commands that discover and
+// extract the links of the markdown files within a directory.
+type extractOptions struct {
+	include   globList
+	exclude   globList
+	changed   string
+	pr        int
+	github    *githubOptions
+	addedOnly bool
+	manifest  string
+}
+
+// addExtractFlags registers the flags used to discover and extract links
+// with the FlagSet.
+func addExtractFlags(fs *flag.FlagSet) *extractOptions {
+	opts := extractOptions{}
+	fs.Var(&opts.include, "i", "Glob of files to include, may be repeated.")
+	fs.Var(&opts.exclude, "e", "Glob of files or directories to exclude, may be repeated.")
+	fs.StringVar(&opts.changed, "changed", "", "Range of revisions to only extract the files changed within.")
+	fs.IntVar(&opts.pr, "pr", 0, "Number of a pull request to only extract the files it changes.")
+	opts.github = addGithubFlags(fs)
+	fs.BoolVar(&opts.addedOnly, "added", false, "Only extract the links found on added or modified lines.")
+	fs.StringVar(&opts.manifest, "manifest", "", "File to remember the hash and links of each file in between runs.")
+	return &opts
+}
+
+// extractCommand lists the links found within the markdown files of a directory.
+func extractCommand(args []string) int {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	opts := addExtractFlags(fs)
+	out := fs.String("out", "", "File to write the links to.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, extractUsage)
+	}
+	fs.Parse(args)
+	defer opts.github.saveResponses()
+
+	links, err := opts.extract(fs.Arg(0))
+	var incomplete *mcheck.IncompleteError
+	if err != nil && !errors.As(err, &incomplete) {
+		log.Printf("Failed to extract links: %v\n", err)
+		return 1
+	}
+	status := writeOutput(*out, func(w io.Writer) error {
+		if links == nil {
+			links = []markdown.Link{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(links)
+	})
+	if incomplete != nil {
+		// The links of the files that could be parsed are still written,
+		// but the run is not mistaken for a complete one.
+		return 1
+	}
+	return status
+}
+
+// extract gathers the links of every markdown file within the root
+// directory, which defaults to the current directory. When a range of
+// revisions or a pull request has been given, only the changed files,
+// and optionally only the changed lines, are used. Files that cannot be
+// parsed are returned within an *mcheck.IncompleteError, along with the
+// links of the others.
+func (opts *extractOptions) extract(root string) ([]markdown.Link, error) {
+	if root == "" {
+		root = "."
+	}
+
+	files, set, err := opts.files(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if set != nil && opts.manifest != "" {
+		return nil, errors.New("a manifest cannot be combined with a range of revisions or a pull request")
+	}
+	links, errs := opts.extractFiles(files)
+	for _, err := range errs {
+		log.Printf("Failed to parse file: %v\n", err)
+	}
+
+	if set != nil && opts.addedOnly {
+		var added []markdown.Link
+		for _, link := range links {
+			if set.ContainsLine(resolvePath(link.File), link.Line) {
+				added = append(added, link)
+			}
+		}
+		links = added
+	}
+	if len(errs) > 0 {
+		return links, &mcheck.IncompleteError{Errors: errs}
+	}
+	return links, nil
+}
+
+// files finds the markdown files within the root directory, which defaults
+// to the current directory, narrowed down to the changed files when a range
+// of revisions or a pull request has been given, which is returned as well.
+func (opts *extractOptions) files(root string) ([]string, changes.Set, error) {
+	if root == "" {
+		root = "."
+	}
+
+	files, err := directory.MarkdownFiles(root, opts.include, opts.exclude)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read files from directory: %w", err)
+	}
+
+	set, err := opts.changes(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not find changed files: %w", err)
+	}
+	if set == nil {
+		return files, nil, nil
+	}
+	var changed []string
+	for _, file := range files {
+		if set.ContainsFile(resolvePath(file)) {
+			changed = append(changed, file)
+		}
+	}
+	return changed, set, nil
+}
+
+// extractFiles extracts the links of the files, parsing only the files that
+// have changed since the previous run when a manifest has been given.
+func (opts *extractOptions) extractFiles(files []string) ([]markdown.Link, []error) {
+	if opts.manifest == "" {
+		return markdown.ExtractBatch(files)
+	}
+
+	m, err := manifest.Open(opts.manifest)
+	if err != nil {
+		return nil, []error{err}
+	}
+	links, stats, errs := m.Extract(files)
+	fmt.Fprintf(os.Stderr, "[+] Parsed %d Changed Files, Reused %d Unchanged Files\n", stats.Parsed, stats.Reused)
+	if err := m.Save(); err != nil {
+		errs = append(errs, fmt.Errorf("saving manifest: %w", err))
+	}
+	return links, errs
+}
+
+// changes finds the files that have changed within the range of revisions
+// or pull request, returning nil when neither has been given.
+func (opts *extractOptions) changes(root string) (changes.Set, error) {
+	ctx := context.Background()
+	switch {
+	case opts.changed != "":
+		return changes.Git(ctx, root, opts.changed)
+	case opts.pr != 0:
+		if opts.github.owner == "" || opts.github.reponame == "" {
+			return nil, errors.New("the repository owner and name must be set to use a pull request")
+		}
+		myRepo, err := opts.github.connect()
+		if err != nil {
+			return nil, err
+		}
+		set, err := myRepo.PullRequestFiles(ctx, opts.pr)
+		if err != nil {
+			return nil, err
+		}
+
+		// The files of a pull request are relative to the root of the
+		// repository, which is the working tree holding the directory
+		// when there is one.
+		base := root
+		if top, err := changes.TopLevel(ctx, root); err == nil {
+			base = top
+		}
+		return set.Resolve(resolvePath(base))
+	}
+	return nil, nil
+}
+
+// resolvePath returns the absolute path of a file with any symbolic links
+// evaluated, so that it can be compared with the paths reported by git.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+	"github.com/jwhitt3r/m-check/internal/repo"
+)
+
+var fetchUsage = `Usage: m-check fetch [mandatory...] [options...]
+
+Downloads the markdown documentation of a repository into
+<basepath>/<owner>/<repository>/, ready to be used by extract or check.
+
+Mandatory:
+	-o Owner of the repository you would like to search.
+	-r Repository that you would like to search in.
+
+Optional:
+	-t Your GitHub Personal Token if you would like to have a higher level of searchers.
+	-b Used to specify the Base Path to save your documents, by default this will be ./docs.
+	-p Used to specify the remote documentation location, by default this will be "docs".
+` + timeoutUsage + enterpriseUsage + `
+Examples:
+	Example For Downloading Content: ./m-check fetch -o jwhitt3r -r m-check -b ./docs
+`
+
+// fetchCommand downloads the markdown documentation of a repository.
+func fetchCommand(args []string) int {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	gh := addGithubFlags(fs)
+	basepath := fs.String("b", "./docs", "Used to specify the Base Path to save your documents")
+	remotepath := fs.String("p", "docs", "Used to specify the remote documentation location")
+	timeout := fs.Duration("timeout", 0, "Longest the whole run may take.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, fetchUsage)
+	}
+	fs.Parse(args)
+	defer gh.saveResponses()
+
+	if gh.owner == "" {
+		return commandUsage(fs, "The repository owner has not been set")
+	}
+	if gh.reponame == "" {
+		return commandUsage(fs, "The repository name has not been set")
+	}
+
+	myRepo, err := gh.connect()
+	if err != nil {
+		log.Printf("Failed to connect to GitHub: %v\n", err)
+		return 1
+	}
+	ctx, cancel := runContext(*timeout)
+	defer cancel()
+	if err := fetch(ctx, myRepo, *basepath, *remotepath); err != nil {
+		log.Printf("Failed to save the documentation: %v\n", err)
+		return 1
+	}
+	fmt.Printf("[+] Documentation Saved To %s\n", directory.FilePathTemplate(*basepath, myRepo.Owner, myRepo.RepoName))
+	return 0
+}
+
+// fetch finds every markdown file within the remote path of the connected
+// repository and saves them within the base path.
+func fetch(ctx context.Context, myRepo *repo.Repository, basepath string, remotepath string) error {
+	var FilesDownloadURL []string
+
+	fmt.Println("[+] Finding Repository")
+	err := myRepo.GithubContents(ctx, remotepath, &FilesDownloadURL)
+	var partial *repo.PartialError
+	switch {
+	case errors.As(err, &partial):
+		for _, err := range partial.Errors {
+			log.Printf("Failed to find every file, the documentation is incomplete: %v\n", explain(err))
+		}
+	case err != nil:
+		return fmt.Errorf("finding documentation: %w", explain(err))
+	}
+
+	fmt.Println("[+] Saving All Documentation Found")
+	err = directory.CreateDirectory(directory.FilePathTemplate(basepath, myRepo.Owner, myRepo.RepoName))
+	if err != nil {
+		return fmt.Errorf("making a new directory: %w", err)
+	}
+	return myRepo.FetchAndCreate(ctx, basepath, FilesDownloadURL)
+}
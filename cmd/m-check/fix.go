@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/jwhitt3r/m-check/internal/fix"
+	"github.com/jwhitt3r/m-check/internal/report"
+)
+
+var fixUsage = `
+Fixing Redirected Links:
+	-fix Rewrite the links that are permanently redirected, by a 301 or 308, to the URL they are
+	     redirected to, changing nothing else within the markdown files.
+	-dry-run Write the changes -fix would make as a unified diff to the standard output, leaving
+	         the files untouched. The results must then be written to a file with -out.
+	-fix-patch File to write the changes to as a unified diff, or "-" for the standard output, in
+	           place of rewriting the files. The patch can be applied with git apply.
+`
+
+// fixOptions holds the flags used to rewrite redirected links.
+type fixOptions struct {
+	rewrite bool
+	dryRun  bool
+	patch   string
+}
+
+// addFixFlags registers the flags used to rewrite redirected links with the
+// FlagSet.
+func addFixFlags(fs *flag.FlagSet) *fixOptions {
+	opts := fixOptions{}
+	fs.BoolVar(&opts.rewrite, "fix", false, "Rewrite the links that are permanently redirected.")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Write the changes -fix would make as a unified diff.")
+	fs.StringVar(&opts.patch, "fix-patch", "", "File to write the changes to as a unified diff.")
+	return &opts
+}
+
+// validate reports what is wrong with the combination of flags, if anything,
+// where out is the output the results are written to. The patch and the
+// results cannot both be written to the standard output, as they could not
+// be told apart.
+func (opts *fixOptions) validate(out string) string {
+	if opts.dryRun && !opts.rewrite {
+		return "-dry-run can only be given along with -fix"
+	}
+	if opts.dryRun && opts.patch != "" {
+		return "-dry-run and -fix-patch cannot both be given"
+	}
+	if (opts.dryRun || opts.patch == "-") && (out == "" || out == "-") {
+		return "The patch is written to the standard output, so the results must be written to a file with -out"
+	}
+	return ""
+}
+
+// apply rewrites the permanently redirected links of the results, or writes
+// the changes as a unified diff on a dry run or when a patch is asked for.
+// Files that cannot be read are logged rather than stopping the others from
+// being fixed.
+func (opts *fixOptions) apply(rep *report.Report) error {
+	if !opts.rewrite && opts.patch == "" {
+		return nil
+	}
+
+	files, errs := fix.Rewrite(rep.Results)
+	for _, err := range errs {
+		log.Printf("Failed to fix file: %v\n", err)
+	}
+	fixed := 0
+	for _, f := range files {
+		fixed += f.Fixed
+	}
+
+	patch := opts.patch
+	if opts.dryRun {
+		patch = "-"
+	}
+	if patch != "" {
+		write := func(w io.Writer) error {
+			for _, f := range files {
+				if err := f.Diff(w); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if status := writeOutput(patch, write); status != 0 {
+			return fmt.Errorf("writing patch %s", patch)
+		}
+		fmt.Fprintf(os.Stderr, "[+] Found %d Permanently Redirected Links To Fix In %d Files\n", fixed, len(files))
+		return nil
+	}
+
+	for _, f := range files {
+		if err := f.Save(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "[+] Fixed %d Permanently Redirected Links In %d Files\n", fixed, len(files))
+	return nil
+}
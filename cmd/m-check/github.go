@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/repo"
+	"github.com/jwhitt3r/m-check/internal/source"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+var enterpriseUsage = `
+GitHub Authentication:
+	Rather than passing a token with -t, which can be seen within the list of running processes,
+	the token may be read from the GITHUB_TOKEN environment variable or from a file.
+	-token-file File holding the token, read in place of -t.
+	-app-id ID of a GitHub App to authenticate as an installation of, in place of a token.
+	-app-key File holding the private key of the GitHub App.
+	-app-installation ID of the installation of the GitHub App, by default the installation on the repository,
+	                  or on the organisation or user account (-o) when no repository is given.
+	Once authenticated, links to repositories, files, issues, pull requests, releases and commits on
+	GitHub are validated through the API, which also finds links to branches that have been deleted.
+	Links to lines of a file on a branch, e.g., #L42-L50, are given a suggested permalink.
+	-check-lines Also check that the lines such links are anchored to still exist within the file.
+
+GitHub Rate Limit:
+	-wait-rate-limit Longest to wait, e.g., 15m, for the rate limit of the GitHub API to reset once
+	it has been exhausted. By default the command fails straight away.
+	-api-cache File to remember the responses of the GitHub API in between runs, e.g.,
+	           ~/.cache/m-check-api.json. Responses requested again are only counted against the
+	           rate limit when they have changed. The raw contents of files are never kept.
+
+GitHub Enterprise Server:
+	-github-url Base URL of a GitHub Enterprise Server instance, e.g., https://github.example.com/api/v3/.
+	-upload-url Upload URL of the GitHub Enterprise Server instance, by default the same as -github-url.
+`
+
+// githubOptions holds the flags used to connect to a repository on GitHub,
+// or on a GitHub Enterprise Server instance.
+type githubOptions struct {
+	owner           string
+	reponame        string
+	token           string
+	baseURL         string
+	uploadURL       string
+	maxWait         time.Duration
+	tokenFile       string
+	appID           int64
+	appKey          string
+	appInstallation int64
+	checkLines      bool
+	apiCache        string
+	// responses is opened by the first connection to GitHub, and shared by
+	// every connection made afterwards.
+	responses *repo.ResponseCache
+}
+
+// addGithubFlags registers the flags used to connect to GitHub with the FlagSet.
+func addGithubFlags(fs *flag.FlagSet) *githubOptions {
+	opts := githubOptions{}
+	fs.StringVar(&opts.owner, "o", "", "Used to specify the owner of the repository.")
+	fs.StringVar(&opts.reponame, "r", "", "Used to specify the Repository that you would like to search in.")
+	fs.StringVar(&opts.token, "t", "", "Used to specify Your GitHub Personal Token.")
+	fs.StringVar(&opts.baseURL, "github-url", "", "Base URL of a GitHub Enterprise Server instance.")
+	fs.StringVar(&opts.uploadURL, "upload-url", "", "Upload URL of a GitHub Enterprise Server instance.")
+	fs.DurationVar(&opts.maxWait, "wait-rate-limit", 0, "Longest to wait for the rate limit of the GitHub API to reset.")
+	fs.StringVar(&opts.tokenFile, "token-file", "", "File holding the token, read in place of -t.")
+	fs.Int64Var(&opts.appID, "app-id", 0, "ID of a GitHub App to authenticate as.")
+	fs.StringVar(&opts.appKey, "app-key", "", "File holding the private key of the GitHub App.")
+	fs.Int64Var(&opts.appInstallation, "app-installation", 0, "ID of the installation of the GitHub App.")
+	fs.BoolVar(&opts.checkLines, "check-lines", false, "Check that the lines links to GitHub files are anchored to still exist.")
+	fs.StringVar(&opts.apiCache, "api-cache", "", "File to remember the responses of the GitHub API in between runs.")
+	return &opts
+}
+
+// loadToken fills in the token from the token file when it has not been
+// given with -t, and then from the GITHUB_TOKEN environment variable when
+// env is true, which is only meant for GitHub.
+func (opts *githubOptions) loadToken(env bool) error {
+	if opts.token == "" && opts.tokenFile != "" {
+		data, err := ioutil.ReadFile(opts.tokenFile)
+		if err != nil {
+			return fmt.Errorf("reading token file: %w", err)
+		}
+		opts.token = strings.TrimSpace(string(data))
+	}
+	if opts.token == "" && env {
+		opts.token = os.Getenv("GITHUB_TOKEN")
+	}
+	return nil
+}
+
+// authenticated reports whether a token or GitHub App has been given.
+func (opts *githubOptions) authenticated() (bool, error) {
+	if err := opts.loadToken(true); err != nil {
+		return false, err
+	}
+	return opts.token != "" || opts.appID != 0, nil
+}
+
+// connect creates a connection to the repository.
+func (opts *githubOptions) connect() (*repo.Repository, error) {
+	sourceOpts, err := opts.sourceOptions("github", opts.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	p, err := source.New(sourceOpts)
+	if err != nil {
+		return nil, err
+	}
+	opts.responses = sourceOpts.Responses
+	return p.(*source.GitHub).Repository(), nil
+}
+
+// sourceOptions describes the connection to the repository hosted by the
+// provider, whose API is found at baseURL. The token is only read from the
+// GITHUB_TOKEN environment variable for GitHub, and the flags that only
+// apply to GitHub are rejected by source.New for any other provider.
+func (opts *githubOptions) sourceOptions(provider string, baseURL string) (source.Options, error) {
+	if err := opts.loadToken(provider == "github"); err != nil {
+		return source.Options{}, err
+	}
+	sourceOpts := source.Options{
+		Provider:  provider,
+		BaseURL:   baseURL,
+		Token:     opts.token,
+		Owner:     opts.owner,
+		Repo:      opts.reponame,
+		MaxWait:   opts.maxWait,
+		Responses: opts.responses,
+	}
+	if provider == "github" {
+		sourceOpts.UploadURL = opts.uploadURL
+	}
+	if opts.appID != 0 {
+		if opts.appKey == "" {
+			return source.Options{}, errors.New("the private key of the GitHub App must be set with -app-key")
+		}
+		data, err := ioutil.ReadFile(opts.appKey)
+		if err != nil {
+			return source.Options{}, fmt.Errorf("reading GitHub App private key: %w", err)
+		}
+		key, err := repo.ParsePrivateKey(data)
+		if err != nil {
+			return source.Options{}, err
+		}
+		sourceOpts.App = &repo.App{ID: opts.appID, PrivateKey: key, InstallationID: opts.appInstallation}
+	}
+	if opts.apiCache != "" && opts.responses == nil {
+		c, err := repo.OpenResponseCache(opts.apiCache)
+		if err != nil {
+			return source.Options{}, err
+		}
+		sourceOpts.Responses = c
+	}
+	return sourceOpts, nil
+}
+
+// saveResponses writes the responses of the GitHub API back to disk. A
+// cache that cannot be saved only costs the next run some of its rate limit,
+// so the failure is logged rather than failing the run.
+func (opts *githubOptions) saveResponses() {
+	if opts.responses == nil {
+		return
+	}
+	if err := opts.responses.Save(); err != nil {
+		log.Printf("Failed to save the API cache: %v\n", err)
+	}
+}
+
+// validateLinks has the checker validate links to GitHub through the API,
+// rather than requesting them anonymously, when a token or GitHub App has
+// been given. The installation of a GitHub App cannot be found without an
+// owner, in which case links are requested as usual.
+func (opts *githubOptions) validateLinks(checker *urlcheck.URLChecker) error {
+	ok, err := opts.authenticated()
+	if err != nil || !ok {
+		return err
+	}
+	if opts.appID != 0 && opts.appInstallation == 0 && opts.owner == "" {
+		fmt.Fprintf(os.Stderr, "[!] Requesting Links To GitHub As Usual, Set -app-installation Or -o To Validate Them Through The GitHub App\n")
+		return nil
+	}
+	myRepo, err := opts.connect()
+	if err != nil {
+		return err
+	}
+	validator := myRepo.LinkValidator()
+	validator.CheckLines = opts.checkLines
+	checker.Use(validator)
+	return nil
+}
+
+// explain adds advice on how to avoid an exhausted rate limit to an error.
+func explain(err error) error {
+	var rateLimit *repo.RateLimitError
+	if errors.As(err, &rateLimit) {
+		return fmt.Errorf("%w, set a token with -t for a higher rate limit, or -wait-rate-limit to wait for it to reset", err)
+	}
+	return err
+}
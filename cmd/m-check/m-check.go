@@ -7,11 +7,15 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/jwhitt3r/m-check/internal/markdown"
 	"github.com/jwhitt3r/m-check/internal/platform/directory"
 	"github.com/jwhitt3r/m-check/internal/repo"
 	"github.com/jwhitt3r/m-check/internal/urlcheck"
@@ -27,7 +31,32 @@ var (
 	l = flag.Bool("l", false, "Used to specify a local scan, this indicates that you have already downloaded the documentation.")
 )
 
+// commands maps the name of each subcommand to the function that runs it,
+// which returns the exit status of the command.
+var commands = map[string]func(args []string) int{
+	"fetch":   fetchCommand,
+	"extract": extractCommand,
+	"check":   checkCommand,
+	"report":  reportCommand,
+	"publish": publishCommand,
+	"scan":    scanCommand,
+	"diff":    diffCommand,
+}
+
 var usage = `Usage: m-check [mandatory...] [options...]
+       m-check <command> [options...]
+       m-check <repository URL> [options...]
+
+Commands:
+	fetch   Download the markdown documentation of a repository.
+	extract List the links found within markdown files as JSON.
+	check   Check the links of a directory or of a list of links.
+	report  Render a saved results file into another format.
+	publish Publish a saved results file to GitHub.
+	scan    Check the documentation of a remote repository, or of every repository of an account.
+	diff    List the links that changed between two saved results files.
+
+	Run "m-check <command> -h" for the options of each command.
 
 Mandatory:
 	-o Owner of the repository you would like to search.
@@ -50,12 +79,26 @@ Examples:
 	Example For Saving To Non-Default Destination: ./m-check -o jwhitt3r -r m-check -b ./tmp
 
 	Example For Non-Default Remote Directory ./m-check -o jwhitt3r -r test_repo -p "documentation"
+
+	Example For Checking A Local Working Tree: ./m-check check ./
+
+	Example For Scanning A Repository From Its URL: ./m-check https://github.com/jwhitt3r/m-check/tree/main/docs
 `
 
 func main() {
-	var FilesDownloadURL []string
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+		// A repository URL scans the repository, with any flags that
+		// follow it passed on to the scan command.
+		if isRepositoryURL(os.Args[1]) {
+			os.Exit(scanCommand(append(os.Args[2:], os.Args[1])))
+		}
+	}
+
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, fmt.Sprintf(usage))
+		fmt.Fprint(os.Stderr, usage)
 	}
 
 	flag.Parse()
@@ -82,28 +125,27 @@ func main() {
 	client := http.Client{Timeout: 5 * time.Second}
 	checker := urlcheck.NewURLCheck(&client)
 	if local == false {
-
-		fmt.Println("[+] Finding Repository")
 		myRepo.NewGithubConnection()
-
-		myRepo.GithubContents(context.Background(), remotepath, &FilesDownloadURL)
-
-		fmt.Println("[+] Saving All Documentation Found")
-		err := directory.CreateDirectory(directory.FilePathTemplate(basepath, myRepo.Owner, myRepo.RepoName))
-		if err != nil {
-			log.Fatalf("An error occurred while making a new directory: %v\n", err)
+		if err := fetch(context.Background(), myRepo, basepath, remotepath); err != nil {
+			log.Printf("Failed to save the documentation: %v\n", err)
+			os.Exit(1)
 		}
-		myRepo.FetchAndCreate(basepath, FilesDownloadURL)
-
 	}
 
 	fmt.Println("[+] Gathering Filenames")
-	files := myRepo.FileNames(basepath)
+	files, err := myRepo.FileNames(basepath)
+	if err != nil {
+		log.Printf("Failed to gather filenames: %v\n", err)
+		os.Exit(1)
+	}
 
-	links := myRepo.ParseBatch(basepath, files)
+	links, errs := myRepo.ParseBatch(context.Background(), basepath, files)
+	for _, err := range errs {
+		log.Printf("Failed to parse file: %v\n", err)
+	}
 
 	fmt.Println("[+] Checking Connectivity Of Markdown Links")
-	webConnectionResponse := checker.URLCheckBatch(links)
+	webConnectionResponse := checkLinks(checker, len(files), links)
 
 	fmt.Printf("[+] Findings Are Saved To %s/output.txt", basepath)
 	for _, val := range webConnectionResponse {
@@ -112,6 +154,34 @@ func main() {
 
 }
 
+// checkLinks checks each link, showing the progress made on the standard
+// error, and returns the formatted outcome of each check in the order
+// URLCheckBatch would.
+func checkLinks(checker *urlcheck.URLChecker, files int, links []string) []string {
+	p, _ := startProgress("auto", false)
+	p.Parsed(files)
+	queue := make(chan markdown.Link)
+	go func() {
+		defer close(queue)
+		for _, link := range links {
+			queue <- markdown.Link{URL: link}
+		}
+	}()
+
+	var results []urlcheck.Result
+	for result := range p.Results(checker.Stream(context.Background(), p.Links(queue))) {
+		results = append(results, result)
+	}
+	p.Stop()
+
+	urlcheck.Sort(results)
+	var webConnectionResponse []string
+	for _, result := range results {
+		webConnectionResponse = append(webConnectionResponse, result.String())
+	}
+	return webConnectionResponse
+}
+
 // A simple function to present the usage of flags when running the command.
 // This is typically called when there are not enough flags have been passed at runtime.
 func usageAndExit(msg string) {
@@ -122,3 +192,80 @@ func usageAndExit(msg string) {
 	flag.Usage()
 	os.Exit(1)
 }
+
+// isRepositoryURL reports whether the argument is the URL of a repository
+// rather than a flag or command.
+func isRepositoryURL(arg string) bool {
+	return strings.HasPrefix(arg, "https://") || strings.HasPrefix(arg, "http://")
+}
+
+// commandUsage presents the usage of a subcommand's flags along with an
+// optional message, and returns the exit status the subcommand should use.
+func commandUsage(fs *flag.FlagSet, msg string) int {
+	if msg != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", msg)
+	}
+	fs.Usage()
+	return 1
+}
+
+// globList collects the globs passed through a repeated flag, where each
+// flag may also hold a comma separated list of globs.
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(value string) error {
+	*g = append(*g, strings.Split(value, ",")...)
+	return nil
+}
+
+// openInput opens the named file for reading, where an empty name or "-"
+// reads from the standard input instead.
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// createOutput creates the named file for writing, where an empty name or
+// "-" writes to the standard output instead.
+func createOutput(name string) (io.WriteCloser, error) {
+	if name == "" || name == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(name)
+}
+
+// nopWriteCloser prevents the standard output from being closed once a
+// subcommand has finished writing to it.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// writeOutput runs the write function against the named output, logging
+// and returning a non-zero exit status when the output cannot be written.
+func writeOutput(name string, write func(w io.Writer) error) int {
+	w, err := createOutput(name)
+	if err != nil {
+		log.Printf("Failed to create output file: %v\n", err)
+		return 1
+	}
+	if err := write(w); err != nil {
+		w.Close()
+		log.Printf("Failed to write output: %v\n", err)
+		return 1
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
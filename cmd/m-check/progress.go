@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/progress"
+	"github.com/jwhitt3r/m-check/internal/source"
+)
+
+var progressUsage = `	-progress How the progress of the run is shown on the standard error, one of auto, bar, log or off.
+	          By default auto, which draws a bar on a terminal and otherwise logs a line every 10s.
+`
+
+// progressModes lists the values of the -progress flag.
+var progressModes = []string{"auto", "bar", "log", "off"}
+
+// addProgressFlag registers the flag choosing how progress is shown with the
+// FlagSet.
+func addProgressFlag(fs *flag.FlagSet) *string {
+	return fs.String("progress", "auto", "How the progress of the run is shown.")
+}
+
+// startProgress starts showing the progress of the run in the mode, returning
+// nil when it is off. A bar would be torn apart by results streamed to the
+// same terminal, so lines are logged in its place. Whatever is logged while
+// a bar is shown is written through it, so that the bar is cleared first.
+func startProgress(mode string, streamToTerminal bool) (*progress.Progress, error) {
+	var bar bool
+	switch mode {
+	case "off":
+		return nil, nil
+	case "auto":
+		bar = progress.IsTerminal(os.Stderr) && !streamToTerminal
+	case "bar":
+		bar = true
+	case "log":
+	default:
+		return nil, fmt.Errorf("unknown progress mode %q, expected one of %s", mode, strings.Join(progressModes, ", "))
+	}
+	p := progress.New(os.Stderr, bar)
+	if bar {
+		log.SetOutput(p)
+	}
+	p.Start()
+	return p, nil
+}
+
+// statusOutput returns where the progress messages of a run are written,
+// which is through the Progress when there is one.
+func statusOutput(p *progress.Progress) io.Writer {
+	if p == nil {
+		return os.Stderr
+	}
+	return p
+}
+
+// isTerminalOutput reports whether the named output is the standard output
+// of a terminal.
+func isTerminalOutput(name string) bool {
+	return (name == "" || name == "-") && progress.IsTerminal(os.Stdout)
+}
+
+// countFiles returns the number of files the links were found within.
+func countFiles(links []markdown.Link) int {
+	files := make(map[string]bool)
+	for _, link := range links {
+		files[link.Repository+"\x00"+link.File] = true
+	}
+	return len(files)
+}
+
+// countingProvider counts each file fetched from the Provider as parsed.
+type countingProvider struct {
+	source.Provider
+	progress *progress.Progress
+}
+
+func (c countingProvider) Fetch(ctx context.Context, path string, ref string) (io.ReadCloser, error) {
+	f, err := c.Provider.Fetch(ctx, path, ref)
+	if err == nil {
+		c.progress.Parsed(1)
+	}
+	return f, err
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/changes"
+	"github.com/jwhitt3r/m-check/internal/repo"
+	"github.com/jwhitt3r/m-check/internal/report"
+)
+
+var publishUsage = `Usage: m-check publish [mandatory...] [options...] [results]
+
+Publishes a results file, saved by the check command in the json format, to
+GitHub. The results are read from the standard input when no file, or "-", is
+given.
+
+Mandatory:
+	-o Owner of the repository to publish to.
+	-r Repository to publish to.
+	-t Your GitHub Personal Token, or GITHUB_TOKEN, -token-file or a GitHub App (-app-id and -app-key).
+
+Pull Request:
+	-pr Number of the pull request to leave a summary comment on, which is updated in place on later runs.
+	-review Also leave a review comment on each changed line of the pull request holding a broken link.
+
+Tracking Issue:
+	-issue Open an issue listing the broken links, which is updated on later runs and closed once every link works.
+	-issue-title Title of the tracking issue, by default "Broken documentation links".
+	-issue-label Label to add to the tracking issue when it is opened, may be repeated.
+
+Check Run:
+	-check-run Publish a check run with an annotation on each line holding a broken link.
+	-check-name Name of the check run, by default "m-check".
+	-sha Commit to publish the check run against, by default the commit checked out within -root.
+
+Optional:
+	-root Root of the repository the results were gathered from, by default the git working tree
+	      holding the current directory. File paths are published relative to this directory.
+` + enterpriseUsage + `
+Examples:
+	Example For Commenting On A Pull Request: ./m-check publish -o jwhitt3r -r m-check -t 12345678975336985 -pr 12 -review results.json
+
+	Example For Tracking Broken Links In An Issue: ./m-check publish -o jwhitt3r -r m-check -t 12345678975336985 -issue results.json
+
+	Example For Publishing A Check Run: ./m-check publish -o jwhitt3r -r m-check -t 12345678975336985 -check-run -sha 4e1243b results.json
+`
+
+// publishOptions holds the flags of the publish command.
+type publishOptions struct {
+	github *githubOptions
+	root   string
+	pr     int
+	review bool
+
+	issue       bool
+	issueTitle  string
+	issueLabels globList
+
+	checkRun  bool
+	checkName string
+	sha       string
+}
+
+// publishCommand publishes a saved results file to GitHub.
+func publishCommand(args []string) int {
+	var opts publishOptions
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	opts.github = addGithubFlags(fs)
+	fs.StringVar(&opts.root, "root", "", "Root of the repository the results were gathered from.")
+	fs.IntVar(&opts.pr, "pr", 0, "Number of the pull request to comment on.")
+	fs.BoolVar(&opts.review, "review", false, "Leave a review comment on each changed line holding a broken link.")
+	fs.BoolVar(&opts.issue, "issue", false, "Open an issue listing the broken links.")
+	fs.StringVar(&opts.issueTitle, "issue-title", "Broken documentation links", "Title of the tracking issue.")
+	fs.Var(&opts.issueLabels, "issue-label", "Label to add to the tracking issue, may be repeated.")
+	fs.BoolVar(&opts.checkRun, "check-run", false, "Publish a check run with an annotation on each line holding a broken link.")
+	fs.StringVar(&opts.checkName, "check-name", "m-check", "Name of the check run.")
+	fs.StringVar(&opts.sha, "sha", "", "Commit to publish the check run against.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, publishUsage)
+	}
+	fs.Parse(args)
+	defer opts.github.saveResponses()
+
+	if opts.github.owner == "" {
+		return commandUsage(fs, "The repository owner has not been set")
+	}
+	if opts.github.reponame == "" {
+		return commandUsage(fs, "The repository name has not been set")
+	}
+	if ok, err := opts.github.authenticated(); err != nil {
+		log.Printf("Failed to read credentials: %v\n", err)
+		return 1
+	} else if !ok {
+		return commandUsage(fs, "A GitHub Personal Token or GitHub App is needed to publish results")
+	}
+	if opts.pr == 0 && !opts.issue && !opts.checkRun {
+		return commandUsage(fs, "Nowhere to publish the results to has been set")
+	}
+
+	rep, err := readReport(fs.Arg(0))
+	if err != nil {
+		log.Printf("Failed to read results: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	relativeTo(ctx, rep, opts.root)
+
+	myRepo, err := opts.github.connect()
+	if err != nil {
+		log.Printf("Failed to connect to GitHub: %v\n", err)
+		return 1
+	}
+
+	if opts.pr != 0 {
+		if err := publishPullRequest(ctx, myRepo, rep, opts); err != nil {
+			log.Printf("Failed to publish to pull request %d: %v\n", opts.pr, err)
+			return 1
+		}
+	}
+	if opts.issue {
+		if err := publishIssue(ctx, myRepo, rep, opts); err != nil {
+			log.Printf("Failed to publish the tracking issue: %v\n", err)
+			return 1
+		}
+	}
+	if opts.checkRun {
+		if err := publishCheckRun(ctx, myRepo, rep, opts); err != nil {
+			log.Printf("Failed to publish the check run: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// publishCheckRun publishes the report as a check run against a commit, with
+// an annotation on each line holding a broken link.
+func publishCheckRun(ctx context.Context, myRepo *repo.Repository, rep *report.Report, opts publishOptions) error {
+	sha := opts.sha
+	if sha == "" {
+		root := opts.root
+		if root == "" {
+			root = "."
+		}
+		var err error
+		if sha, err = changes.Revision(ctx, root, "HEAD"); err != nil {
+			return fmt.Errorf("finding the commit to publish against, set -sha: %w", err)
+		}
+	}
+
+	var summary bytes.Buffer
+	if err := rep.Write(&summary, "markdown"); err != nil {
+		return err
+	}
+
+	broken := rep.Broken()
+	check := repo.CheckRun{
+		Name:    opts.checkName,
+		HeadSHA: sha,
+		Success: len(broken) == 0 && len(rep.Errors) == 0,
+		Title:   fmt.Sprintf("%d of %d links broken", len(broken), len(rep.Results)),
+		Summary: summary.String(),
+	}
+	for _, result := range broken {
+		// Links found within issues, pull requests, releases and
+		// discussions have no file within the commit to annotate.
+		if result.File == "" || result.Line == 0 || strings.Contains(result.File, "://") {
+			continue
+		}
+		check.Annotations = append(check.Annotations, repo.Annotation{
+			Path:    result.File,
+			Line:    result.Line,
+			Title:   "Broken link",
+			Message: fmt.Sprintf("%s: %s", result.URL, report.Status(result)),
+		})
+	}
+
+	run, err := myRepo.PublishCheckRun(ctx, check)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[+] Check Run Published To %s\n", run.GetHTMLURL())
+	return nil
+}
+
+// publishIssue opens, updates or closes the issue tracking the broken links
+// of the repository, depending on whether any links are broken. A report
+// holding errors is incomplete, so it keeps the issue open as well.
+func publishIssue(ctx context.Context, myRepo *repo.Repository, rep *report.Report, opts publishOptions) error {
+	var body bytes.Buffer
+	if err := rep.Write(&body, "markdown"); err != nil {
+		return err
+	}
+
+	broken := len(rep.Broken()) > 0 || len(rep.Errors) > 0
+	issue, err := myRepo.SyncTrackingIssue(ctx, repo.TrackingIssue{
+		Title:  opts.issueTitle,
+		Body:   body.String(),
+		Labels: opts.issueLabels,
+	}, broken)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case issue == nil:
+		fmt.Println("[+] No Broken Links To Track")
+	case broken:
+		fmt.Printf("[+] Broken Links Tracked In %s\n", issue.GetHTMLURL())
+	default:
+		fmt.Printf("[+] Every Link Is Working, Closed %s\n", issue.GetHTMLURL())
+	}
+	return nil
+}
+
+// publishPullRequest leaves a summary of the report as a comment on the pull
+// request, along with review comments on the changed lines holding broken links.
+func publishPullRequest(ctx context.Context, myRepo *repo.Repository, rep *report.Report, opts publishOptions) error {
+	var body bytes.Buffer
+	if err := rep.Write(&body, "markdown"); err != nil {
+		return err
+	}
+	if _, err := myRepo.UpsertSummaryComment(ctx, opts.pr, body.String()); err != nil {
+		return err
+	}
+	fmt.Printf("[+] Summary Published To Pull Request %d\n", opts.pr)
+
+	if !opts.review {
+		return nil
+	}
+
+	// Review comments can only be left on lines that are known to be part
+	// of the diff of the pull request.
+	set, err := myRepo.PullRequestFiles(ctx, opts.pr)
+	if err != nil {
+		return err
+	}
+	var comments []repo.ReviewComment
+	for _, result := range rep.Broken() {
+		if !set[result.File][result.Line] {
+			continue
+		}
+		comments = append(comments, repo.ReviewComment{
+			Path: result.File,
+			Line: result.Line,
+			Body: fmt.Sprintf("Broken link %s: %s", result.URL, report.Status(result)),
+		})
+	}
+	created, err := myRepo.CreateReviewComments(ctx, opts.pr, comments)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[+] %d Review Comments Published To Pull Request %d\n", created, opts.pr)
+	return nil
+}
+
+// relativeTo rewrites the file of each local result to be slash separated
+// and relative to the root of the repository, which defaults to the git
+// working tree holding the current directory. Files outside of the root are
+// kept as they are.
+func relativeTo(ctx context.Context, rep *report.Report, root string) {
+	if root == "" {
+		root = "."
+		if top, err := changes.TopLevel(ctx, root); err == nil {
+			root = top
+		}
+	}
+	root = resolvePath(root)
+
+	for i, result := range rep.Results {
+		// Files of remote repositories are already relative to their root.
+		if result.File == "" || result.Repository != "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, resolvePath(result.File))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rep.Results[i].File = filepath.ToSlash(rel)
+	}
+}
+
+// readReport reads a results file that has been saved by the check command.
+func readReport(name string) (*report.Report, error) {
+	f, err := openInput(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return report.Read(f)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/report"
+)
+
+var reportUsage = `Usage: m-check report [options...] [results]
+
+Renders a results file, saved by the check command in the json format, into
+another format. The results are read from the standard input when no file,
+or "-", is given.
+
+Optional:
+	-f Format of the report, one of ` + strings.Join(report.Formats, ", ") + `. By default markdown.
+	-out File to write the report to, by default the standard output.
+
+Examples:
+	Example For Rendering A Markdown Summary: ./m-check report -f markdown -out summary.md results.json
+`
+
+// reportCommand renders a saved results file into another format.
+func reportCommand(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("f", "markdown", "Format of the report.")
+	out := fs.String("out", "", "File to write the report to.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, reportUsage)
+	}
+	fs.Parse(args)
+
+	rep, err := readReport(fs.Arg(0))
+	if err != nil {
+		log.Printf("Failed to read results: %v\n", err)
+		return 1
+	}
+
+	return writeOutput(*out, func(w io.Writer) error { return rep.Write(w, *format) })
+}
@@ -0,0 +1,504 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	mcheck "github.com/jwhitt3r/m-check"
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/progress"
+	"github.com/jwhitt3r/m-check/internal/repo"
+	"github.com/jwhitt3r/m-check/internal/report"
+	"github.com/jwhitt3r/m-check/internal/source"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+	"github.com/jwhitt3r/m-check/internal/wiki"
+)
+
+var scanUsage = `Usage: m-check scan [mandatory...] [options...]
+       m-check scan [options...] <repository URL>
+
+Scans the markdown documentation of a remote repository, or of every repository
+of an organisation or user account, without saving the documentation to disk.
+The results of every repository are combined into a single report, and a URL
+found within many repositories is only checked once.
+
+The repository may be given by its URL, such as
+https://github.com/jwhitt3r/m-check/tree/main/docs, from which the provider,
+owner, repository, ref and path are taken. The provider of a self-hosted
+instance must still be set with -provider.
+
+Mandatory, unless a repository URL is given:
+	-o Owner of the repository, or the organisation or user account to scan every repository of.
+
+Optional:
+	-r Repository to scan, by default every repository of the account is scanned.
+	-t Your GitHub Personal Token if you would like to have a higher level of searchers.
+	-p Used to specify the remote documentation location, by default this will be "docs".
+	-ref Branch, tag or commit to scan, by default the default branch.
+	-wiki Also scan the wiki of each GitHub repository, where [[Page Name]] links are resolved against its pages.
+	-docs Scan the markdown documentation of each repository. By default true, set -docs=false to only scan the sources below.
+	-f Format of the results, one of ` + strings.Join(report.Formats, ", ") + `. By default text.
+	-out File to write the results to, by default the standard output.
+` + timeoutUsage + streamUsage + progressUsage + `
+Repository Filters:
+	-archived Include archived repositories, which are skipped by default.
+	-forks Include forked repositories, which are skipped by default.
+	-topic Only include repositories with the topic, may be repeated to require many topics.
+	-match Only include repositories whose name matches the glob, may be repeated.
+
+Issues, Pull Requests, Releases And Discussions Of GitHub Repositories:
+	-issues Also scan the bodies of issues.
+	-pulls Also scan the bodies of pull requests.
+	-releases Also scan the release notes of releases.
+	-discussions Also scan the bodies of discussions.
+	-label Only scan issues and pull requests with the label, may be repeated to require many labels.
+	-state Only scan issues and pull requests in the state, one of open, closed or all. By default open.
+	Links found within them are located by the URL of the issue, pull request, release or discussion.
+` + enterpriseUsage + cacheUsage + baselineUsage + `
+Other Source Providers:
+	-provider Hosting service of the repository, one of ` + strings.Join(source.Providers, ", ") + `. By default github.
+	-api-url Base URL of the API of a self-hosted instance, e.g., https://gitlab.example.com/api/v4.
+	Only a single repository, set with -r, can be scanned outside of GitHub. The token
+	(-t) is a GitLab or Gitea access token, or a Bitbucket access token or username:app-password.
+	-wait-rate-limit, -app-id and -api-cache only apply to GitHub.
+
+Examples:
+	Example For Scanning A Single Repository: ./m-check scan -o jwhitt3r -r m-check
+
+	Example For Scanning An Organisation: ./m-check scan -o my-org -t 12345678975336985 -topic docs -f markdown -out summary.md
+
+	Example For Scanning A GitLab Project: ./m-check scan -provider gitlab -o my-group/my-subgroup -r my-project
+
+	Example For Scanning From A URL: ./m-check scan https://github.com/jwhitt3r/m-check/tree/main/docs
+
+	Example For A Nightly Scan Reusing Recent Outcomes: ./m-check scan -o my-org -cache ~/.cache/m-check.json -cache-ttl 72h
+
+	Example For Scanning Release Notes And Pinned Issues: ./m-check scan -docs=false -releases -issues -label pinned -o jwhitt3r -r m-check
+`
+
+// scanCommand checks the links of the markdown documentation of one or many
+// remote repositories.
+func scanCommand(args []string) int {
+	var filter repo.RepositoryFilter
+	var bodies repo.BodyFilter
+	var topics, names, labels globList
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	gh := addGithubFlags(fs)
+	remotepath := fs.String("p", "docs", "Used to specify the remote documentation location")
+	ref := fs.String("ref", "", "Branch, tag or commit to scan.")
+	provider := fs.String("provider", "github", "Hosting service of the repository.")
+	apiURL := fs.String("api-url", "", "Base URL of the API of a self-hosted instance.")
+	scanWikis := fs.Bool("wiki", false, "Also scan the wiki of each GitHub repository.")
+	scanDocs := fs.Bool("docs", true, "Scan the markdown documentation of each repository.")
+	fs.BoolVar(&bodies.Issues, "issues", false, "Also scan the bodies of issues.")
+	fs.BoolVar(&bodies.PullRequests, "pulls", false, "Also scan the bodies of pull requests.")
+	fs.BoolVar(&bodies.Releases, "releases", false, "Also scan the release notes of releases.")
+	fs.BoolVar(&bodies.Discussions, "discussions", false, "Also scan the bodies of discussions.")
+	fs.Var(&labels, "label", "Only scan issues and pull requests with the label, may be repeated.")
+	fs.StringVar(&bodies.State, "state", "open", "Only scan issues and pull requests in the state.")
+	format := fs.String("f", "text", "Format of the results.")
+	out := fs.String("out", "", "File to write the results to.")
+	timeout := fs.Duration("timeout", 0, "Longest the whole run may take.")
+	stream := fs.Bool("stream", false, "Write each result as soon as it has been checked.")
+	progressMode := addProgressFlag(fs)
+	cacheOpts := addCacheFlags(fs)
+	baselineOpts := addBaselineFlags(fs)
+	fs.BoolVar(&filter.Archived, "archived", false, "Include archived repositories.")
+	fs.BoolVar(&filter.Forks, "forks", false, "Include forked repositories.")
+	fs.Var(&topics, "topic", "Only include repositories with the topic, may be repeated.")
+	fs.Var(&names, "match", "Only include repositories whose name matches the glob, may be repeated.")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, scanUsage)
+	}
+	fs.Parse(args)
+	defer gh.saveResponses()
+	filter.Topics = topics
+	filter.Names = names
+	bodies.Labels = labels
+
+	if fs.NArg() > 0 {
+		explicit := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+		hint := ""
+		if explicit["provider"] {
+			hint = *provider
+		}
+		loc, err := source.ParseURL(fs.Arg(0), hint)
+		if err != nil {
+			return commandUsage(fs, fmt.Sprintf("The repository URL is not valid: %v", err))
+		}
+		*provider = loc.Provider
+		gh.owner, gh.reponame = loc.Owner, loc.Repo
+		if loc.BaseURL != "" && *apiURL == "" {
+			*apiURL = loc.BaseURL
+		}
+		if loc.Ref != "" {
+			*ref = loc.Ref
+		}
+		if loc.Path != "" || loc.Ref != "" {
+			*remotepath = loc.Path
+		}
+	}
+
+	if gh.owner == "" {
+		return commandUsage(fs, "The repository owner has not been set")
+	}
+	if *stream && (*format != "text" || baselineOpts.path != "") {
+		return commandUsage(fs, "Only the text format can be streamed, without a baseline")
+	}
+
+	ctx, cancel := runContext(*timeout)
+	defer cancel()
+	providers, err := scanProviders(ctx, gh, *provider, *apiURL, filter)
+	if err != nil {
+		log.Printf("Failed to find the repositories to scan: %v\n", err)
+		return 1
+	}
+
+	client := http.Client{Timeout: 5 * time.Second}
+	urlChecker := urlcheck.NewURLCheck(&client)
+	if *provider == "github" {
+		if err := gh.validateLinks(urlChecker); err != nil {
+			log.Printf("Failed to connect to GitHub: %v\n", err)
+			return 1
+		}
+	}
+	c, err := cacheOpts.use(urlChecker)
+	if err != nil {
+		log.Printf("Failed to open the cache: %v\n", err)
+		return 1
+	}
+
+	p, err := startProgress(*progressMode, *stream && isTerminalOutput(*out))
+	if err != nil {
+		return commandUsage(fs, fmt.Sprintf("The progress mode is not valid: %v", err))
+	}
+
+	var reporters []mcheck.Reporter
+	var streamed io.WriteCloser
+	if *stream {
+		if streamed, err = createOutput(*out); err != nil {
+			log.Printf("Failed to create output file: %v\n", err)
+			return 1
+		}
+		defer streamed.Close()
+		reporters = append(reporters, streamReporter(streamed))
+	}
+
+	// The links of each repository are checked as soon as they are found,
+	// while the following repositories are still being fetched.
+	scanned := &scanExtractor{
+		providers: providers,
+		path:      *remotepath,
+		ref:       *ref,
+		docs:      *scanDocs,
+		wikis:     *scanWikis,
+		bodies:    bodies,
+		messages:  statusOutput(p),
+		progress:  p,
+	}
+	rep, err := mcheck.Run(ctx, mcheck.Options{
+		Extractor: scanned,
+		Checker:   scanChecker{checker{urlChecker, p}, scanned},
+		Reporters: reporters,
+	})
+	p.Stop()
+	if rep == nil {
+		log.Printf("Failed to check links: %v\n", err)
+		return 1
+	}
+	if err != nil {
+		log.Printf("Stopped early: %v\n", err)
+	}
+	cacheOpts.save(c)
+	if err := baselineOpts.apply(rep); err != nil {
+		log.Printf("Failed to apply the baseline: %v\n", err)
+		return 1
+	}
+
+	if streamed == nil {
+		if status := writeOutput(*out, func(w io.Writer) error { return rep.Write(w, *format) }); status != 0 {
+			return status
+		}
+	}
+	if len(rep.Broken()) > 0 || len(rep.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// scanExtractor adapts the repositories to scan into an
+// mcheck.StreamExtractor, sending the links of the documentation of each
+// repository, and of its issues, pull requests, releases, discussions and
+// wiki when asked for, as soon as they have been found. The files fetched are
+// counted within the progress, which may be nil.
+type scanExtractor struct {
+	providers []source.Provider
+	path      string
+	ref       string
+	docs      bool
+	wikis     bool
+	bodies    repo.BodyFilter
+	// messages is where the repository being scanned is written.
+	messages io.Writer
+	progress *progress.Progress
+
+	// mu guards pages.
+	mu sync.Mutex
+	// pages holds the results of the links between the pages of each wiki,
+	// which are known without being requested.
+	pages map[markdown.Link][]urlcheck.Result
+}
+
+func (e *scanExtractor) Extract(ctx context.Context) ([]markdown.Link, error) {
+	out := make(chan markdown.Link)
+	var err error
+	go func() {
+		err = e.ExtractStream(ctx, out)
+		close(out)
+	}()
+	var links []markdown.Link
+	for link := range out {
+		links = append(links, link)
+	}
+	return links, err
+}
+
+// ExtractStream sends the links of each repository in turn. Whatever could
+// not be fetched is returned within an *mcheck.IncompleteError, so that an
+// incomplete scan is never mistaken for a clean one.
+func (e *scanExtractor) ExtractStream(ctx context.Context, out chan<- markdown.Link) error {
+	var errs []error
+	send := func(found []markdown.Link) {
+		e.progress.Parsed(countFiles(found))
+		for _, link := range found {
+			select {
+			case out <- link:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+	for _, scanned := range e.providers {
+		if ctx.Err() != nil {
+			break
+		}
+		if e.docs {
+			fmt.Fprintf(e.messages, "[+] Scanning %s\n", scanned.Name())
+			if err := source.Stream(ctx, countingProvider{scanned, e.progress}, e.path, e.ref, out); err != nil {
+				failures := []error{err}
+				var partial *source.PartialError
+				if errors.As(err, &partial) {
+					failures = partial.Errors
+				}
+				for _, err := range failures {
+					log.Printf("Failed to scan all of %s: %v\n", scanned.Name(), explain(err))
+					errs = append(errs, fmt.Errorf("%s: %w", scanned.Name(), err))
+				}
+			}
+		}
+
+		g, ok := scanned.(*source.GitHub)
+		if !ok {
+			continue
+		}
+		if e.bodies.Issues || e.bodies.PullRequests || e.bodies.Releases || e.bodies.Discussions {
+			fmt.Fprintf(e.messages, "[+] Scanning The Issues, Pull Requests, Releases And Discussions Of %s\n", scanned.Name())
+			found, err := scanBodies(ctx, g.Repository(), e.bodies)
+			if err != nil {
+				log.Printf("Failed to scan all of %s: %v\n", scanned.Name(), explain(err))
+				errs = append(errs, fmt.Errorf("%s: %w", scanned.Name(), err))
+			}
+			send(found)
+		}
+		if e.wikis {
+			fmt.Fprintf(e.messages, "[+] Scanning The Wiki Of %s\n", scanned.Name())
+			found, results, err := scanWiki(ctx, g.Repository())
+			if err != nil {
+				log.Printf("Skipping the wiki of %s: %v\n", scanned.Name(), err)
+				continue
+			}
+			var pages []markdown.Link
+			e.mu.Lock()
+			if e.pages == nil {
+				e.pages = make(map[markdown.Link][]urlcheck.Result)
+			}
+			for _, result := range results {
+				e.pages[result.Link] = append(e.pages[result.Link], result)
+				pages = append(pages, result.Link)
+			}
+			e.mu.Unlock()
+			send(append(found, pages...))
+		}
+	}
+	if len(errs) > 0 {
+		return &mcheck.IncompleteError{Errors: errs}
+	}
+	return nil
+}
+
+// page returns the result of the link when it is a link between the pages of
+// a wiki, whose result is already known.
+func (e *scanExtractor) page(link markdown.Link) (urlcheck.Result, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	results := e.pages[link]
+	if len(results) == 0 {
+		return urlcheck.Result{}, false
+	}
+	e.pages[link] = results[1:]
+	return results[0], true
+}
+
+// scanChecker checks the links sent by a scanExtractor, passing on the
+// results of the links between the pages of a wiki rather than requesting
+// them.
+type scanChecker struct {
+	checker
+	extractor *scanExtractor
+}
+
+func (c scanChecker) Check(ctx context.Context, links []markdown.Link) ([]urlcheck.Result, error) {
+	var results []urlcheck.Result
+	var unknown []markdown.Link
+	for _, link := range links {
+		if result, ok := c.extractor.page(link); ok {
+			results = append(results, result)
+		} else {
+			unknown = append(unknown, link)
+		}
+	}
+	checked, err := c.checker.Check(ctx, unknown)
+	return append(results, checked...), err
+}
+
+func (c scanChecker) CheckStream(ctx context.Context, links <-chan markdown.Link) <-chan urlcheck.Result {
+	unknown := make(chan markdown.Link)
+	out := make(chan urlcheck.Result)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(unknown)
+		for link := range links {
+			if result, ok := c.extractor.page(link); ok {
+				out <- result
+				continue
+			}
+			select {
+			case unknown <- link:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for result := range c.checker.CheckStream(ctx, unknown) {
+			out <- result
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// scanProviders connects to the repository to scan or, when no repository
+// has been given, to every repository of the GitHub account that passes the
+// filter.
+func scanProviders(ctx context.Context, gh *githubOptions, provider string, apiURL string, filter repo.RepositoryFilter) ([]source.Provider, error) {
+	if provider != "github" {
+		if gh.reponame == "" {
+			return nil, fmt.Errorf("the repository name must be set to scan a %s repository", provider)
+		}
+		sourceOpts, err := gh.sourceOptions(provider, apiURL)
+		if err != nil {
+			return nil, err
+		}
+		p, err := source.New(sourceOpts)
+		if err != nil {
+			return nil, err
+		}
+		return []source.Provider{p}, nil
+	}
+
+	if apiURL != "" && gh.baseURL == "" {
+		gh.baseURL = apiURL
+	}
+	myRepo, err := gh.connect()
+	if err != nil {
+		return nil, err
+	}
+	if gh.reponame != "" {
+		return []source.Provider{source.NewGitHub(myRepo)}, nil
+	}
+
+	repositories, err := myRepo.ListRepositories(ctx, gh.owner, filter)
+	if err != nil {
+		return nil, fmt.Errorf("listing the repositories of %s: %w", gh.owner, err)
+	}
+	fmt.Fprintf(os.Stderr, "[+] Found %d Repositories\n", len(repositories))
+
+	var providers []source.Provider
+	for _, r := range repositories {
+		providers = append(providers, source.NewGitHub(r))
+	}
+	return providers, nil
+}
+
+// scanBodies returns the links of the issues, pull requests, releases and
+// discussions of the repository selected by the filter, each located by the
+// URL it was found within.
+func scanBodies(ctx context.Context, myRepo *repo.Repository, filter repo.BodyFilter) ([]markdown.Link, error) {
+	bodies, err := myRepo.Bodies(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []markdown.Link
+	for _, body := range bodies {
+		for _, link := range markdown.Extract(strings.NewReader(body.Text), body.URL) {
+			link.Repository = myRepo.FullName()
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// scanWiki clones the wiki of the repository into a temporary directory and
+// returns the links of its pages, along with the results of the links
+// between its pages.
+func scanWiki(ctx context.Context, myRepo *repo.Repository) ([]markdown.Link, []urlcheck.Result, error) {
+	tmp, err := ioutil.TempDir("", "m-check-wiki")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	token, err := myRepo.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	dir := filepath.Join(tmp, myRepo.RepoName+".wiki")
+	if err := wiki.Clone(ctx, wiki.Remote(myRepo.WebURL(), myRepo.Owner, myRepo.RepoName), token, dir); err != nil {
+		return nil, nil, err
+	}
+
+	w, err := wiki.Open(dir, myRepo.WebURL()+"/"+myRepo.FullName()+"/wiki", myRepo.FullName()+".wiki")
+	if err != nil {
+		return nil, nil, err
+	}
+	return w.Extract()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+
+	mcheck "github.com/jwhitt3r/m-check"
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/progress"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+var streamUsage = `	-stream Write each result as soon as it has been checked, in the order the checks complete, rather
+	        than once every link has been checked. Only the text format can be streamed, without -baseline.
+`
+
+// checker adapts a URLChecker into an mcheck.StreamChecker, so that links
+// are checked as soon as they have been extracted, counting them within the
+// progress, which may be nil.
+type checker struct {
+	*urlcheck.URLChecker
+	progress *progress.Progress
+}
+
+func (c checker) Check(ctx context.Context, links []markdown.Link) ([]urlcheck.Result, error) {
+	return c.CheckBatchContext(ctx, links)
+}
+
+func (c checker) CheckStream(ctx context.Context, links <-chan markdown.Link) <-chan urlcheck.Result {
+	return c.progress.Results(c.Stream(ctx, c.progress.Links(links)))
+}
+
+// extractor adapts the extract flags into an mcheck.StreamExtractor,
+// gathering the links of the root directory, or of a file of links saved by
+// the extract command, counting the files parsed within the progress, which
+// may be nil.
+type extractor struct {
+	opts      *extractOptions
+	root      string
+	linksFile string
+	progress  *progress.Progress
+}
+
+func (e extractor) Extract(ctx context.Context) ([]markdown.Link, error) {
+	if e.linksFile != "" {
+		return readLinks(e.linksFile)
+	}
+	return e.opts.extract(e.root)
+}
+
+// ExtractStream sends the links of each file as soon as it has been read.
+// Extraction that depends on every file, through a manifest or the changed
+// lines, sends the links once they have all been gathered. Files that cannot
+// be parsed are returned within an *mcheck.IncompleteError.
+func (e extractor) ExtractStream(ctx context.Context, out chan<- markdown.Link) error {
+	if e.linksFile != "" || e.opts.manifest != "" || e.opts.addedOnly {
+		links, err := e.Extract(ctx)
+		e.progress.Parsed(countFiles(links))
+		for _, link := range links {
+			select {
+			case out <- link:
+			case <-ctx.Done():
+				return err
+			}
+		}
+		return err
+	}
+
+	files, _, err := e.opts.files(e.root)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, file := range files {
+		failed := markdown.ExtractStream(ctx, []string{file}, out)
+		if ctx.Err() != nil {
+			break
+		}
+		for _, err := range failed {
+			log.Printf("Failed to parse file: %v\n", err)
+			errs = append(errs, err)
+		}
+		if len(failed) == 0 {
+			e.progress.Parsed(1)
+		}
+	}
+	if len(errs) > 0 {
+		return &mcheck.IncompleteError{Errors: errs}
+	}
+	return nil
+}
+
+// streamReporter writes each result to w as soon as it has been checked.
+func streamReporter(w io.Writer) mcheck.Reporter {
+	return &mcheck.WriterReporter{W: w, Format: "text", Stream: true}
+}
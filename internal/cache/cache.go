@@ -0,0 +1,135 @@
+// Package cache remembers the outcome of checking each URL on disk, so that
+// runs made close together do not request the same URLs again.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// Default lifetimes of the entries of a Cache.
+const (
+	DefaultSuccessTTL = 24 * time.Hour
+	DefaultFailureTTL = time.Hour
+)
+
+// Entry is the outcome of checking a URL and when it was checked.
+type Entry struct {
+	urlcheck.Verdict
+	Checked time.Time
+}
+
+// Cache holds the outcome of checking each URL, keyed by the normalised URL.
+// It satisfies urlcheck.Cache.
+type Cache struct {
+	// SuccessTTL is how long the outcome of a working link is reused for.
+	SuccessTTL time.Duration
+	// FailureTTL is how long the outcome of a broken link is reused for,
+	// which is kept short as the failure may be fleeting.
+	FailureTTL time.Duration
+
+	path string
+	// now is replaced within tests.
+	now func() time.Time
+	// mu guards entries.
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// Open reads the cache saved at path, or starts an empty cache when there
+// is no file at path yet.
+func Open(path string) (*Cache, error) {
+	c := Cache{
+		SuccessTTL: DefaultSuccessTTL,
+		FailureTTL: DefaultFailureTTL,
+		path:       path,
+		now:        time.Now,
+		entries:    make(map[string]Entry),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("decoding cache %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Get returns the outcome of the URL when it was checked recently enough
+// to be reused.
+func (c *Cache) Get(rawurl string) (urlcheck.Verdict, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[Normalise(rawurl)]
+	c.mu.Unlock()
+	if !ok || c.now().Sub(entry.Checked) >= c.ttl(entry.Verdict) {
+		return urlcheck.Verdict{}, false
+	}
+	return entry.Verdict, true
+}
+
+// Put remembers the outcome of the URL, which is checked now.
+func (c *Cache) Put(rawurl string, v urlcheck.Verdict) {
+	c.mu.Lock()
+	c.entries[Normalise(rawurl)] = Entry{Verdict: v, Checked: c.now()}
+	c.mu.Unlock()
+}
+
+// ttl returns how long the outcome is reused for.
+func (c *Cache) ttl(v urlcheck.Verdict) time.Duration {
+	if (urlcheck.Result{StatusCode: v.StatusCode, Error: v.Error}).Broken() {
+		return c.FailureTTL
+	}
+	return c.SuccessTTL
+}
+
+// Save writes the cache back to its path, dropping the entries that have
+// expired.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	now := c.now()
+	for key, entry := range c.entries {
+		if now.Sub(entry.Checked) >= c.ttl(entry.Verdict) {
+			delete(c.entries, key)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return directory.WriteFileAtomic(c.path, data, 0644)
+}
+
+// Normalise returns the form of the URL that keys the cache, so that URLs
+// differing only in the case of their scheme or host, a default port or an
+// empty path share an entry. A URL that cannot be parsed is left as it is.
+func Normalise(rawurl string) string {
+	u, err := url.Parse(strings.TrimSpace(rawurl))
+	if err != nil || u.Host == "" {
+		return rawurl
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestCacheTTL(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to open an empty cache : %v", failure, err)
+	}
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return start }
+	c.Put("https://example.com/ok", urlcheck.Verdict{StatusCode: http.StatusOK})
+	c.Put("https://example.com/missing", urlcheck.Verdict{StatusCode: http.StatusNotFound})
+	c.Put("https://unreachable.example.com", urlcheck.Verdict{Error: "no such host"})
+
+	tt := []struct {
+		url     string
+		elapsed time.Duration
+		cached  bool
+	}{
+		{"https://example.com/ok", 2 * time.Hour, true},
+		{"HTTPS://Example.com:443/ok", 2 * time.Hour, true},
+		{"https://example.com/ok", 25 * time.Hour, false},
+		{"https://example.com/missing", 30 * time.Minute, true},
+		{"https://example.com/missing", 2 * time.Hour, false},
+		{"https://unreachable.example.com/", 30 * time.Minute, true},
+		{"https://example.com/unknown", 0, false},
+	}
+
+	t.Log("Given the need to reuse recent outcomes between runs")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen asking for %q after %v", testID, test.url, test.elapsed)
+		{
+			c.now = func() time.Time { return start.Add(test.elapsed) }
+			if _, ok := c.Get(test.url); ok == test.cached {
+				t.Logf("\t%s\tTest %d:\tShould find it cached: %v", success, testID, test.cached)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould find it cached: %v : %v", failure, testID, test.cached, ok)
+			}
+		}
+	}
+}
+
+func TestCacheSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "m-check", "cache.json")
+	c, err := Open(path)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to open an empty cache : %v", failure, err)
+	}
+	c.Put("https://example.com/ok", urlcheck.Verdict{StatusCode: http.StatusOK, Suggestion: "https://example.com/permalink"})
+	c.Put("https://example.com/stale", urlcheck.Verdict{StatusCode: http.StatusNotFound})
+	c.entries["https://example.com/stale"] = Entry{Verdict: urlcheck.Verdict{StatusCode: http.StatusNotFound}, Checked: time.Now().Add(-2 * time.Hour)}
+
+	t.Log("Given the need to keep outcomes on disk between runs")
+	{
+		if err := c.Save(); err != nil {
+			t.Fatalf("\t%s\tShould be able to save the cache : %v", failure, err)
+		}
+		t.Logf("\t%s\tShould be able to save the cache.", success)
+
+		saved, err := Open(path)
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to open the saved cache : %v", failure, err)
+		}
+		if v, ok := saved.Get("https://example.com/ok"); ok && v.Suggestion == "https://example.com/permalink" {
+			t.Logf("\t%s\tShould read back the outcome of the working link.", success)
+		} else {
+			t.Errorf("\t%s\tShould read back the outcome of the working link : %+v", failure, v)
+		}
+		if len(saved.entries) == 1 {
+			t.Logf("\t%s\tShould drop the expired outcome.", success)
+		} else {
+			t.Errorf("\t%s\tShould drop the expired outcome : %d entries", failure, len(saved.entries))
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package changes works out which markdown files, and which lines within
+// them, have changed between two revisions of a repository so that only
+// those links need to be checked.
+package changes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Lines holds the line numbers, starting from 1, that have been added or
+// modified within a file.
+type Lines map[int]bool
+
+// Set maps the path of each changed file to the lines that have been added
+// or modified within it. A file mapped to nil lines has changed, but which
+// lines have changed is unknown, for example for a large or binary patch.
+type Set map[string]Lines
+
+// ContainsFile reports whether the file has changed.
+func (s Set) ContainsFile(path string) bool {
+	_, ok := s[path]
+	return ok
+}
+
+// ContainsLine reports whether the line of the file has been added or
+// modified. Every line of a file with unknown lines is treated as changed.
+func (s Set) ContainsLine(path string, line int) bool {
+	lines, ok := s[path]
+	if !ok {
+		return false
+	}
+	return lines == nil || lines[line]
+}
+
+// Resolve joins every path within the Set onto the base directory, turning
+// paths that are relative to the root of a repository into ones that can be
+// compared with the files found on disk.
+func (s Set) Resolve(base string) (Set, error) {
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(Set, len(s))
+	for path, lines := range s {
+		resolved[filepath.Join(base, filepath.FromSlash(path))] = lines
+	}
+	return resolved, nil
+}
+
+// Git runs git diff over the range of revisions, e.g., main..feature, within
+// the repository holding dir, and returns the files and lines that have been
+// added or modified. The paths of the returned Set are absolute.
+func Git(ctx context.Context, dir string, revisions string) (Set, error) {
+	top, err := TopLevel(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := git(ctx, dir, "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff",
+		"--unified=0", "--diff-filter=ACMR", revisions, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := ParseDiff(strings.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+	return set.Resolve(top)
+}
+
+// TopLevel returns the root directory of the git working tree holding dir.
+func TopLevel(ctx context.Context, dir string) (string, error) {
+	top, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(top), nil
+}
+
+// Revision resolves a revision, e.g., HEAD, of the repository holding dir
+// into the SHA of its commit.
+func Revision(ctx context.Context, dir string, revision string) (string, error) {
+	sha, err := git(ctx, dir, "rev-parse", "--verify", revision+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sha), nil
+}
+
+// git runs a git command within dir and returns its standard output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[len(args)-1], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// ParseDiff reads a unified diff, such as the output of git diff, and returns
+// the files and lines that have been added or modified. The paths of the
+// returned Set are slash separated and relative to the root of the repository.
+func ParseDiff(r io.Reader) (Set, error) {
+	set := make(Set)
+	var file string
+	var h hunk
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if h.remaining() {
+			h.add(set[file], line)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			var err error
+			if file, err = parseName(strings.TrimPrefix(line, "+++ ")); err != nil {
+				return nil, err
+			}
+			if file == "/dev/null" {
+				file = ""
+				continue
+			}
+			set[file] = Lines{}
+		case strings.HasPrefix(line, "diff "):
+			file = ""
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			if h, err = parseHunk(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return set, scanner.Err()
+}
+
+// parseName reads the name of the new file from a "+++" line, dropping the
+// "b/" prefix. Git quotes a name holding unusual characters like a C string,
+// e.g., "b/caf\303\251.md", and ends a name holding a space with a tab, after
+// which diff writes the time the file was modified.
+func parseName(name string) (string, error) {
+	if strings.HasPrefix(name, `"`) {
+		unquoted, err := strconv.Unquote(strings.TrimRight(name, "\t"))
+		if err != nil {
+			return "", fmt.Errorf("malformed file name %s: %w", name, err)
+		}
+		name = unquoted
+	} else if i := strings.Index(name, "\t"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimPrefix(name, "b/"), nil
+}
+
+// ParsePatch reads the hunks of a single file's patch, as returned by the
+// GitHub API for the files of a pull request, and returns the lines that
+// have been added or modified.
+func ParsePatch(patch string) (Lines, error) {
+	lines := Lines{}
+	var h hunk
+	for _, line := range strings.Split(patch, "\n") {
+		if h.remaining() {
+			h.add(lines, line)
+			continue
+		}
+		if strings.HasPrefix(line, "@@ ") {
+			var err error
+			if h, err = parseHunk(line); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return lines, nil
+}
+
+// hunk tracks the position within a single hunk of a unified diff.
+type hunk struct {
+	// line is the line number, within the new file, of the next line
+	// that is either added or kept as context.
+	line int
+	// old and new are the number of lines of the old and new file that
+	// are still to be read from the hunk.
+	old, new int
+}
+
+// parseHunk reads the header of a hunk, e.g., "@@ -10,2 +12,3 @@".
+func parseHunk(header string) (hunk, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return hunk{}, fmt.Errorf("malformed hunk header %q", header)
+	}
+
+	_, oldCount, err := parseRange(fields[1][1:])
+	if err != nil {
+		return hunk{}, fmt.Errorf("malformed hunk header %q: %w", header, err)
+	}
+	line, newCount, err := parseRange(fields[2][1:])
+	if err != nil {
+		return hunk{}, fmt.Errorf("malformed hunk header %q: %w", header, err)
+	}
+	return hunk{line: line, old: oldCount, new: newCount}, nil
+}
+
+// parseRange reads the start and count of one side of a hunk header, where
+// a missing count means a single line.
+func parseRange(r string) (int, int, error) {
+	start, count := r, "1"
+	if i := strings.Index(r, ","); i >= 0 {
+		start, count = r[:i], r[i+1:]
+	}
+	s, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	c, err := strconv.Atoi(count)
+	if err != nil {
+		return 0, 0, err
+	}
+	return s, c, nil
+}
+
+// remaining reports whether there are lines of the hunk still to be read.
+func (h *hunk) remaining() bool {
+	return h.old > 0 || h.new > 0
+}
+
+// add reads a single line of the hunk, recording it within the Lines when
+// it has been added. Lines of a deleted file are read with nil Lines.
+func (h *hunk) add(lines Lines, line string) {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		if lines != nil {
+			lines[h.line] = true
+		}
+		h.line++
+		h.new--
+	case strings.HasPrefix(line, "-"):
+		h.old--
+	case strings.HasPrefix(line, "\\"):
+		// "\ No newline at end of file" does not count towards either side.
+	default:
+		h.line++
+		h.old--
+		h.new--
+	}
+}
@@ -0,0 +1,116 @@
+package changes
+
+import (
+	"strings"
+	"testing"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/docs/a.md b/docs/a.md
+index 1111111..2222222 100644
+--- a/docs/a.md
++++ b/docs/a.md
+@@ -2 +2 @@ Title
+-[old](https://example.com/old)
++[new](https://example.com/new)
+@@ -10,0 +11,2 @@
++++ a line that looks like a header
++[more](https://example.com/more)
+diff --git a/docs/gone.md b/docs/gone.md
+deleted file mode 100644
+--- a/docs/gone.md
++++ /dev/null
+@@ -1 +0,0 @@
+-[gone](https://example.com/gone)
+diff --git a/README.md b/README.md
+new file mode 100644
+--- /dev/null
++++ b/README.md
+@@ -0,0 +1 @@
++# README
+` + "diff --git a/docs/my notes.md b/docs/my notes.md\n" +
+		"--- a/docs/my notes.md\t\n" +
+		"+++ b/docs/my notes.md\t\n" +
+		"@@ -3,0 +4 @@\n" +
+		"+[notes](https://example.com/notes)\n" +
+		`diff --git "a/docs/caf\303\251.md" "b/docs/caf\303\251.md"
+--- "a/docs/caf\303\251.md"
++++ "b/docs/caf\303\251.md"
+@@ -1 +1 @@
+-[old](https://example.com/old)
++[new](https://example.com/new)
+`
+
+	tt := []struct {
+		file    string
+		line    int
+		changed bool
+	}{
+		{"docs/a.md", 2, true},
+		{"docs/a.md", 3, false},
+		{"docs/a.md", 11, true},
+		{"docs/a.md", 12, true},
+		{"docs/gone.md", 1, false},
+		{"README.md", 1, true},
+		{"docs/my notes.md", 4, true},
+		{"docs/café.md", 1, true},
+	}
+
+	t.Log("Given the need to find the lines changed within a diff")
+	set, err := ParseDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse the diff : %v", failure, err)
+	}
+	if len(set) == 4 {
+		t.Logf("\t%s\tShould find 4 changed files.", success)
+	} else {
+		t.Errorf("\t%s\tShould find 4 changed files : %v", failure, set)
+	}
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen checking line %d of %s", testID, test.line, test.file)
+		if set.ContainsLine(test.file, test.line) == test.changed {
+			t.Logf("\t%s\tTest %d:\tShould report changed as %v", success, testID, test.changed)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould report changed as %v", failure, testID, test.changed)
+		}
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	patch := `@@ -1,4 +1,5 @@
+ # Title
+-[old](https://example.com/old)
++[new](https://example.com/new)
++[added](https://example.com/added)
+
+ Footer
+\ No newline at end of file`
+
+	tt := []struct {
+		line    int
+		changed bool
+	}{
+		{1, false},
+		{2, true},
+		{3, true},
+		{4, false},
+		{5, false},
+	}
+
+	t.Log("Given the need to find the lines changed by a pull request")
+	lines, err := ParsePatch(patch)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse the patch : %v", failure, err)
+	}
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen checking line %d", testID, test.line)
+		if lines[test.line] == test.changed {
+			t.Logf("\t%s\tTest %d:\tShould report changed as %v", success, testID, test.changed)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould report changed as %v", failure, testID, test.changed)
+		}
+	}
+}
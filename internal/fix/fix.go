@@ -0,0 +1,222 @@
+// Package fix rewrites the links of local markdown files that have been
+// permanently redirected, so that they point straight at where they moved.
+package fix
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// contextLines is the number of unchanged lines shown around each change of
+// a unified diff.
+const contextLines = 3
+
+// File is a markdown file along with its contents once its links have been
+// rewritten.
+type File struct {
+	// Path is the path of the file.
+	Path string
+	// Before holds the contents of the file as they were read.
+	Before string
+	// After holds the contents of the file with its links rewritten.
+	After string
+	// Fixed is the number of links that were rewritten.
+	Fixed int
+}
+
+// Rewrite rewrites the links of each local file that have been permanently
+// redirected, as a 301 or 308, to the URL they were redirected to. Only the
+// URLs change; everything around them is left exactly as it was. Files left
+// unchanged are not returned. Files that cannot be read do not stop the
+// others from being rewritten, and are reported through the returned slice
+// of errors.
+func Rewrite(results []urlcheck.Result) ([]File, []error) {
+	// replace holds the URLs to rewrite on each line of each file.
+	replace := make(map[string]map[int]map[string]string)
+	for _, result := range results {
+		if !Fixable(result) {
+			continue
+		}
+		lines, ok := replace[result.File]
+		if !ok {
+			lines = make(map[int]map[string]string)
+			replace[result.File] = lines
+		}
+		if lines[result.Line] == nil {
+			lines[result.Line] = make(map[string]string)
+		}
+		lines[result.Line][result.URL] = Target(result)
+	}
+
+	var paths []string
+	for path := range replace {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var files []File
+	var errs []error
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f := File{Path: path, Before: string(data)}
+		lines := strings.SplitAfter(f.Before, "\n")
+		for i, line := range lines {
+			urls, ok := replace[path][i+1]
+			if !ok {
+				continue
+			}
+			if fixed := markdown.ReplaceLinks(line, urls); fixed != line {
+				lines[i] = fixed
+				f.Fixed++
+			}
+		}
+		if f.Fixed > 0 {
+			f.After = strings.Join(lines, "")
+			files = append(files, f)
+		}
+	}
+	return files, errs
+}
+
+// Fixable reports whether the link of the result can be rewritten, which
+// is when it was found within a local file and every redirect it went
+// through was permanent.
+func Fixable(result urlcheck.Result) bool {
+	return result.Permanent && result.Redirect != "" && !result.Broken() &&
+		result.Repository == "" && result.File != "" && !strings.Contains(result.File, "://")
+}
+
+// Target returns the URL the link of the result is rewritten to, which keeps
+// the fragment of the link when the redirect does not have one of its own, as
+// the fragment is never sent to the server.
+func Target(result urlcheck.Result) string {
+	old, err := url.Parse(result.URL)
+	if err != nil || old.Fragment == "" {
+		return result.Redirect
+	}
+	target, err := url.Parse(result.Redirect)
+	if err != nil || target.Fragment != "" {
+		return result.Redirect
+	}
+	target.Fragment = old.Fragment
+	return target.String()
+}
+
+// Save writes the rewritten contents back to the file, keeping its
+// permissions. The file is replaced in a single rename, so that an
+// interrupted fix never leaves it truncated.
+func (f File) Save() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return err
+	}
+	return directory.WriteFileAtomic(f.Path, []byte(f.After), info.Mode().Perm())
+}
+
+// Diff writes the changes made to the file as a unified diff, which can be
+// applied with git apply or patch -p1. An absolute path is named as it is,
+// without the a/ and b/ prefixes, which can be applied with patch -p0.
+func (f File) Diff(w io.Writer) error {
+	before := diffLines(f.Before)
+	after := diffLines(f.After)
+	from, to := diffNames(f.Path)
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", from, to); err != nil {
+		return err
+	}
+
+	// Only the contents of lines change, so both sides hold the same number
+	// of lines, and each hunk covers the same lines on both sides.
+	var changed []int
+	for i := range before {
+		if before[i] != after[i] {
+			changed = append(changed, i)
+		}
+	}
+	for len(changed) > 0 {
+		end := 1
+		for end < len(changed) && changed[end]-changed[end-1] <= 2*contextLines {
+			end++
+		}
+		first := changed[0] - contextLines
+		if first < 0 {
+			first = 0
+		}
+		last := changed[end-1] + contextLines + 1
+		if last > len(before) {
+			last = len(before)
+		}
+		if err := hunk(w, before, after, first, last); err != nil {
+			return err
+		}
+		changed = changed[end:]
+	}
+	return nil
+}
+
+// diffNames returns the names the file is given on either side of a diff.
+func diffNames(path string) (string, string) {
+	name := filepath.ToSlash(filepath.Clean(path))
+	if filepath.IsAbs(path) {
+		return name, name
+	}
+	return "a/" + name, "b/" + name
+}
+
+// hunk writes the lines from first up to last as a single hunk.
+func hunk(w io.Writer, before, after []string, first, last int) error {
+	if _, err := fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", first+1, last-first, first+1, last-first); err != nil {
+		return err
+	}
+	for i := first; i < last; {
+		if before[i] == after[i] {
+			if _, err := io.WriteString(w, " "+before[i]); err != nil {
+				return err
+			}
+			i++
+			continue
+		}
+		// A run of changed lines is shown as the lines removed followed by
+		// the lines added.
+		run := i
+		for run < last && before[run] != after[run] {
+			run++
+		}
+		for _, line := range before[i:run] {
+			if _, err := io.WriteString(w, "-"+line); err != nil {
+				return err
+			}
+		}
+		for _, line := range after[i:run] {
+			if _, err := io.WriteString(w, "+"+line); err != nil {
+				return err
+			}
+		}
+		i = run
+	}
+	return nil
+}
+
+// diffLines splits the contents into lines as a unified diff shows them,
+// each ending with a newline, and a missing final newline marked.
+func diffLines(contents string) []string {
+	lines := strings.SplitAfter(contents, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n\\ No newline at end of file\n"
+	return lines
+}
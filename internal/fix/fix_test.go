@@ -0,0 +1,141 @@
+package fix
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	doc := "# Title\r\n" +
+		"See [old](https://example.com/old) and [kept](https://example.com/found).\r\n" +
+		"1\n2\n3\n4\n5\n6\n7\n8\n" +
+		"[Section](https://example.com/old#usage)"
+	if err := ioutil.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatalf("\t%s\tShould be able to write the markdown file : %v", failure, err)
+	}
+
+	moved := func(url string, line int) urlcheck.Result {
+		return urlcheck.Result{
+			Link:       markdown.Link{URL: url, File: path, Line: line},
+			StatusCode: http.StatusOK,
+			Redirect:   "https://example.com/new",
+			Permanent:  true,
+		}
+	}
+	found := moved("https://example.com/found", 2)
+	found.Permanent = false
+	results := []urlcheck.Result{
+		moved("https://example.com/old", 2),
+		found,
+		moved("https://example.com/old#usage", 11),
+	}
+
+	t.Log("Given links that have been permanently redirected")
+	files, errs := Rewrite(results)
+	if len(errs) > 0 || len(files) != 1 {
+		t.Fatalf("\t%s\tShould rewrite a single file : %v %v", failure, files, errs)
+	}
+	f := files[0]
+
+	want := "# Title\r\n" +
+		"See [old](https://example.com/new) and [kept](https://example.com/found).\r\n" +
+		"1\n2\n3\n4\n5\n6\n7\n8\n" +
+		"[Section](https://example.com/new#usage)"
+	if f.After == want && f.Fixed == 2 {
+		t.Logf("\t%s\tShould only rewrite the permanently redirected links, keeping their fragment.", success)
+	} else {
+		t.Errorf("\t%s\tShould only rewrite the permanently redirected links, keeping their fragment : %d %q", failure, f.Fixed, f.After)
+	}
+
+	var diff bytes.Buffer
+	if err := f.Diff(&diff); err != nil {
+		t.Fatalf("\t%s\tShould be able to write a diff : %v", failure, err)
+	}
+	name := filepath.ToSlash(path)
+	wantDiff := "--- " + name + "\n+++ " + name + "\n" +
+		"@@ -1,5 +1,5 @@\n" +
+		" # Title\r\n" +
+		"-See [old](https://example.com/old) and [kept](https://example.com/found).\r\n" +
+		"+See [old](https://example.com/new) and [kept](https://example.com/found).\r\n" +
+		" 1\n 2\n 3\n" +
+		"@@ -8,4 +8,4 @@\n" +
+		" 6\n 7\n 8\n" +
+		"-[Section](https://example.com/old#usage)\n\\ No newline at end of file\n" +
+		"+[Section](https://example.com/new#usage)\n\\ No newline at end of file\n"
+	if diff.String() == wantDiff {
+		t.Logf("\t%s\tShould describe the changes as a unified diff.", success)
+	} else {
+		t.Errorf("\t%s\tShould describe the changes as a unified diff : %q", failure, diff.String())
+	}
+
+	if err := f.Save(); err != nil {
+		t.Fatalf("\t%s\tShould be able to save the file : %v", failure, err)
+	}
+	if data, err := ioutil.ReadFile(path); err == nil && string(data) == want {
+		t.Logf("\t%s\tShould save the rewritten file.", success)
+	} else {
+		t.Errorf("\t%s\tShould save the rewritten file : %q %v", failure, data, err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() == 0644 {
+		t.Logf("\t%s\tShould keep the permissions of the file.", success)
+	} else {
+		t.Errorf("\t%s\tShould keep the permissions of the file : %v %v", failure, info.Mode(), err)
+	}
+}
+
+func TestDiffNames(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "README.md")
+	tt := []struct {
+		path string
+		from string
+		to   string
+	}{
+		{"./docs/README.md", "a/docs/README.md", "b/docs/README.md"},
+		{abs, filepath.ToSlash(abs), filepath.ToSlash(abs)},
+	}
+
+	t.Log("Given the need to name the file on either side of a diff")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the path is %s", testID, test.path)
+		if from, to := diffNames(test.path); from == test.from && to == test.to {
+			t.Logf("\t%s\tTest %d:\tShould name it %s and %s.", success, testID, test.from, test.to)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould name it %s and %s : %s %s", failure, testID, test.from, test.to, from, to)
+		}
+	}
+}
+
+func TestFixable(t *testing.T) {
+	tt := []struct {
+		name   string
+		result urlcheck.Result
+		want   bool
+	}{
+		{"permanent", urlcheck.Result{Link: markdown.Link{File: "README.md"}, StatusCode: http.StatusOK, Redirect: "https://example.com/new", Permanent: true}, true},
+		{"temporary", urlcheck.Result{Link: markdown.Link{File: "README.md"}, StatusCode: http.StatusOK, Redirect: "https://example.com/new"}, false},
+		{"broken", urlcheck.Result{Link: markdown.Link{File: "README.md"}, StatusCode: http.StatusNotFound, Redirect: "https://example.com/new", Permanent: true}, false},
+		{"remote", urlcheck.Result{Link: markdown.Link{File: "README.md", Repository: "jwhitt3r/m-check"}, StatusCode: http.StatusOK, Redirect: "https://example.com/new", Permanent: true}, false},
+		{"issue", urlcheck.Result{Link: markdown.Link{File: "https://github.com/jwhitt3r/m-check/issues/1"}, StatusCode: http.StatusOK, Redirect: "https://example.com/new", Permanent: true}, false},
+	}
+
+	t.Log("Given the need to only rewrite links that have moved for good")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the link is %s", testID, test.name)
+		if got := Fixable(test.result); got == test.want {
+			t.Logf("\t%s\tTest %d:\tShould be fixable: %v.", success, testID, test.want)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould be fixable: %v : %v", failure, testID, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package manifest remembers the links extracted from each markdown file
+// along with a hash of its content, so that only the files that have
+// changed since the previous run need to be parsed again.
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+)
+
+// File is the hash of the content of a file and the links found within it.
+type File struct {
+	Hash  string          `json:"hash"`
+	Links []markdown.Link `json:"links"`
+}
+
+// Manifest holds every file extracted by the previous run, keyed by path.
+type Manifest struct {
+	Files map[string]File `json:"files"`
+
+	path string
+}
+
+// Stats counts the files parsed and reused by Extract.
+type Stats struct {
+	// Parsed is the number of files that were new or had changed.
+	Parsed int
+	// Reused is the number of files whose links were taken from the
+	// manifest.
+	Reused int
+}
+
+// Open reads the manifest saved at path, or starts an empty manifest when
+// there is no file at path yet.
+func Open(path string) (*Manifest, error) {
+	m := Manifest{Files: make(map[string]File), path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decoding manifest %s: %w", path, err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]File)
+	}
+	return &m, nil
+}
+
+// Extract returns the links of the markdown files found at each path,
+// parsing only the files whose content differs from the manifest. The
+// manifest is updated to hold exactly the files given, so that files that
+// have been removed are forgotten. Files that cannot be read are reported
+// through the returned slice of errors rather than stopping the batch.
+func (m *Manifest) Extract(paths []string) ([]markdown.Link, Stats, []error) {
+	var links []markdown.Link
+	var stats Stats
+	var errs []error
+	files := make(map[string]File, len(paths))
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+
+		file, ok := m.Files[path]
+		if ok && file.Hash == hash {
+			stats.Reused++
+		} else {
+			file = File{Hash: hash, Links: markdown.Extract(bytes.NewReader(data), path)}
+			stats.Parsed++
+		}
+		files[path] = file
+		links = append(links, file.Links...)
+	}
+	m.Files = files
+	return links, stats, errs
+}
+
+// Save writes the manifest back to its path.
+func (m *Manifest) Save() error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return directory.WriteFileAtomic(m.path, data, 0644)
+}
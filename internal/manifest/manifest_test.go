@@ -0,0 +1,66 @@
+package manifest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.json")
+	a := filepath.Join(dir, "a.md")
+	b := filepath.Join(dir, "b.md")
+	write := func(name string, content string) {
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("\t%s\tShould be able to write %s : %v", failure, name, err)
+		}
+	}
+	write(a, "[m-check](https://github.com/jwhitt3r/m-check)\n")
+	write(b, "[Go](https://golang.org)\n")
+
+	tt := []struct {
+		change func()
+		paths  []string
+		stats  Stats
+		links  int
+		files  int
+	}{
+		{func() {}, []string{a, b}, Stats{Parsed: 2}, 2, 2},
+		{func() {}, []string{a, b}, Stats{Reused: 2}, 2, 2},
+		{func() { write(b, "[Go](https://golang.org)\n[Docs](https://pkg.go.dev)\n") }, []string{a, b}, Stats{Parsed: 1, Reused: 1}, 3, 2},
+		{func() {}, []string{a}, Stats{Reused: 1}, 1, 1},
+	}
+
+	t.Log("Given the need to only parse the files that have changed since the previous run")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen extracting %d files", testID, len(test.paths))
+		{
+			test.change()
+			m, err := Open(path)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to open the manifest : %v", failure, testID, err)
+			}
+			links, stats, errs := m.Extract(test.paths)
+			if len(errs) != 0 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to extract the files : %v", failure, testID, errs)
+			}
+			if stats == test.stats && len(links) == test.links {
+				t.Logf("\t%s\tTest %d:\tShould find %d links with %+v", success, testID, test.links, test.stats)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould find %d links with %+v : %d with %+v", failure, testID, test.links, test.stats, len(links), stats)
+			}
+			if len(m.Files) == test.files {
+				t.Logf("\t%s\tTest %d:\tShould remember %d files", success, testID, test.files)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould remember %d files : %d", failure, testID, test.files, len(m.Files))
+			}
+			if err := m.Save(); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to save the manifest : %v", failure, testID, err)
+			}
+		}
+	}
+}
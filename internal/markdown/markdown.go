@@ -0,0 +1,180 @@
+// Package markdown extracts the links found within markdown files,
+// independently of where those files have come from.
+package markdown
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The Regex will aim to locate any address that has the following structure:
+// https://github.com/jwhitt3r. An example of this would be within a markdown
+// file as: [Jwhitt3rs GitHub](https://github.com/jwhitt3r) or
+// file as: [Jwhitt3rs GitHub]("https://github.com/jwhitt3r")
+var markdownURL = regexp.MustCompile(`https?://[^()]+?[^)"]+`)
+
+// Link is a URL found within a markdown file, along with where it was found.
+type Link struct {
+	// URL is the address that has been extracted from the file.
+	URL string `json:"url"`
+	// Repository is the owner and name of the remote repository holding
+	// the file, e.g., jwhitt3r/m-check, which is empty for local files.
+	Repository string `json:"repository,omitempty"`
+	// File is the path of the markdown file the URL was found within.
+	File string `json:"file,omitempty"`
+	// Line is the line number, starting from 1, the URL was found on.
+	Line int `json:"line,omitempty"`
+}
+
+// Extract traverses a markdown file and compares a regular expression
+// to find any possible links within the document. The file name is
+// recorded against each Link to report where it was found.
+func Extract(f io.Reader, file string) []Link {
+	var links []Link
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		submatchall := markdownURL.FindAllString(scanner.Text(), -1)
+		for _, element := range submatchall {
+			links = append(links, Link{URL: strings.TrimSpace(element), File: file, Line: line})
+		}
+	}
+
+	return links
+}
+
+// ReplaceLinks returns the line with each link found within it, as Extract
+// would find it, replaced by the URL the replace map holds for it. Links
+// without a replacement, and everything around the links, are left exactly
+// as they were.
+func ReplaceLinks(line string, replace map[string]string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range markdownURL.FindAllStringIndex(line, -1) {
+		match := line[loc[0]:loc[1]]
+		url := strings.TrimSpace(match)
+		replacement, ok := replace[url]
+		if !ok {
+			continue
+		}
+		start := loc[0] + strings.Index(match, url)
+		b.WriteString(line[last:start])
+		b.WriteString(replacement)
+		last = start + len(url)
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// Parse traverses a markdown file and returns only the URLs that have
+// been found within it.
+func Parse(f io.Reader) []string {
+	var links []string
+	for _, link := range Extract(f, "") {
+		links = append(links, link.URL)
+	}
+	return links
+}
+
+// ExtractFile opens the markdown file found at path and returns the
+// links that have been gathered from it.
+func ExtractFile(path string) ([]Link, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Extract(f, path), nil
+}
+
+// ExtractStream extracts the links of the markdown file found at each path
+// in turn, sending each Link to out as soon as its file has been read, so
+// that the links can be checked while the remaining files are still being
+// read. Files that cannot be opened are reported through the returned
+// slice of errors rather than stopping the stream, which stops once the
+// context is done.
+func ExtractStream(ctx context.Context, paths []string, out chan<- Link) []error {
+	var errs []error
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return append(errs, err)
+		}
+		links, err := ExtractFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, link := range links {
+			select {
+			case out <- link:
+			case <-ctx.Done():
+				return append(errs, ctx.Err())
+			}
+		}
+	}
+	return errs
+}
+
+// ExtractBatch wraps a concurrent method for extracting the links of the
+// markdown files found at each path, the outcome of which is appended to
+// a single slice of links ordered by file and line. Files that cannot be
+// opened are reported through the returned slice of errors rather than
+// stopping the batch.
+func ExtractBatch(paths []string) ([]Link, []error) {
+	type result struct {
+		links []Link
+		err   error
+	}
+
+	ch := make(chan result, len(paths))
+	var wg sync.WaitGroup
+	wg.Add(len(paths))
+	for _, path := range paths {
+		go func(path string) {
+			links, err := ExtractFile(path)
+			ch <- result{links: links, err: err}
+			wg.Done()
+		}(path)
+	}
+	wg.Wait()
+	close(ch)
+
+	var links []Link
+	var errs []error
+	for value := range ch {
+		if value.err != nil {
+			errs = append(errs, value.err)
+			continue
+		}
+		links = append(links, value.links...)
+	}
+	Sort(links)
+	return links, errs
+}
+
+// Sort orders the links by the repository and file they were found in,
+// then by the line they were found on, and then by the URL, so that links
+// sharing a line are always listed in the same order.
+func Sort(links []Link) {
+	sort.SliceStable(links, func(i, j int) bool {
+		if links[i].Repository != links[j].Repository {
+			return links[i].Repository < links[j].Repository
+		}
+		if links[i].File != links[j].File {
+			return links[i].File < links[j].File
+		}
+		if links[i].Line != links[j].Line {
+			return links[i].Line < links[j].Line
+		}
+		return links[i].URL < links[j].URL
+	})
+}
@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestExtract(t *testing.T) {
+	doc := `# Title
+[Jwhitt3rs Github](https://github.com/jwhitt3r)
+
+See [one](http://example.com/one) and [two]("https://example.com/two").
+`
+	tt := []Link{
+		{URL: "https://github.com/jwhitt3r", File: "README.md", Line: 2},
+		{URL: "http://example.com/one", File: "README.md", Line: 4},
+		{URL: "https://example.com/two", File: "README.md", Line: 4},
+	}
+
+	t.Log("Given the need to know where each link of a markdown file was found")
+	links := Extract(strings.NewReader(doc), "README.md")
+	if len(links) != len(tt) {
+		t.Fatalf("\t%s\tShould find %d links : %d", failure, len(tt), len(links))
+	}
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen extracting %s", testID, test.URL)
+		if links[testID] == test {
+			t.Logf("\t%s\tTest %d:\tShould be found within %s on line %d", success, testID, test.File, test.Line)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould be found within %s on line %d : %+v", failure, testID, test.File, test.Line, links[testID])
+		}
+	}
+}
+
+func TestReplaceLinks(t *testing.T) {
+	replace := map[string]string{
+		"http://example.com/one":  "https://example.com/1",
+		"https://example.com/two": "https://example.com/2",
+	}
+	tt := []struct {
+		line string
+		want string
+	}{
+		{"See [one](http://example.com/one) and [two](\"https://example.com/two\").", "See [one](https://example.com/1) and [two](\"https://example.com/2\")."},
+		{"Not [one](http://example.com/one/more) or [three](https://example.com/three).", "Not [one](http://example.com/one/more) or [three](https://example.com/three)."},
+		{"No links at all.", "No links at all."},
+	}
+
+	t.Log("Given the need to rewrite links without touching the markdown around them")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen replacing the links of %q", testID, test.line)
+		if got := ReplaceLinks(test.line, replace); got == test.want {
+			t.Logf("\t%s\tTest %d:\tShould only replace the links that have a replacement.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould only replace the links that have a replacement : %q", failure, testID, got)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	links := []Link{
+		{URL: "https://example.com/b", File: "README.md", Line: 4},
+		{URL: "https://example.com/c", File: "docs/index.md", Line: 1},
+		{URL: "https://example.com/a", File: "README.md", Line: 4},
+		{URL: "https://example.com/d", File: "README.md", Line: 2},
+	}
+	want := []string{"https://example.com/d", "https://example.com/a", "https://example.com/b", "https://example.com/c"}
+
+	t.Log("Given the need to list the links in the same order on every run")
+	Sort(links)
+	for testID, url := range want {
+		t.Logf("Test %d:\tWhen sorting %s", testID, url)
+		if links[testID].URL == url {
+			t.Logf("\t%s\tTest %d:\tShould be ordered by file, line and then URL.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould be ordered by file, line and then URL : %+v", failure, testID, links[testID])
+		}
+	}
+}
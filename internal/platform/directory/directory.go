@@ -5,8 +5,10 @@ package directory
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 // CreateDirectory creates a new directory to store the Github Repository
@@ -14,8 +16,7 @@ import (
 func CreateDirectory(path string) error {
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
-		os.MkdirAll(path, 1)
-		return nil
+		return os.MkdirAll(path, 0755)
 	}
 	return err
 }
@@ -41,3 +42,33 @@ func OutputToFile(path string, val string) error {
 	}
 	return nil
 }
+
+// WriteFileAtomic writes the data to the file at path with the permissions,
+// creating the directories leading to it. The file is replaced in a single
+// rename, so that a run that is interrupted never leaves a truncated file
+// behind.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
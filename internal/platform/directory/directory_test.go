@@ -0,0 +1,37 @@
+package directory
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+
+	t.Log("Given the need to save state without leaving a truncated file behind")
+	for testID, contents := range []string{`{"first": true}`, `{}`} {
+		t.Logf("Test %d:\tWhen writing %s", testID, contents)
+		{
+			if err := WriteFileAtomic(path, []byte(contents), 0644); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to write the file : %v", failure, testID, err)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err == nil && string(data) == contents {
+				t.Logf("\t%s\tTest %d:\tShould replace the contents of the file.", success, testID)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould replace the contents of the file : %q %v", failure, testID, data, err)
+			}
+			files, _ := ioutil.ReadDir(filepath.Dir(path))
+			if len(files) == 1 {
+				t.Logf("\t%s\tTest %d:\tShould leave no temporary files behind.", success, testID)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould leave no temporary files behind : %d files", failure, testID, len(files))
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package directory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/platform/gitignore"
+)
+
+// DefaultInclude holds the globs used to find markdown files when
+// no include globs have been given.
+var DefaultInclude = []string{"*.md", "*.markdown"}
+
+// MarkdownFiles recursively walks the root directory and returns the path
+// of every file matching one of the include globs and none of the exclude
+// globs. Any .gitignore file found along the way is respected, as are those
+// of the directories between the root of the enclosing repository and the
+// root directory, along with the repository's .git/info/exclude file. The
+// .git directories are never entered.
+func MarkdownFiles(root string, include []string, exclude []string) ([]string, error) {
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+
+	ignore, prefix, err := repositoryIgnores(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		// The patterns are matched against the path within the repository,
+		// while the globs are matched against the path within the root.
+		name := within(prefix, rel)
+
+		if info.IsDir() {
+			if rel == "." {
+				return addIgnoreFile(ignore, path, prefix)
+			}
+			if info.Name() == ".git" || ignore.Ignored(name, true) || matchAny(exclude, rel) {
+				return filepath.SkipDir
+			}
+			return addIgnoreFile(ignore, path, name)
+		}
+
+		if ignore.Ignored(name, false) || matchAny(exclude, rel) || !matchAny(include, rel) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// repositoryIgnores looks for the git repository enclosing the root directory
+// and reads its .git/info/exclude file, along with the .gitignore file of
+// every directory above the root, into a Matcher. The slash separated path of
+// the root within the repository is returned alongside, which is empty when
+// the root is the top of the repository or is not within one at all.
+func repositoryIgnores(root string) (*gitignore.Matcher, string, error) {
+	ignore := gitignore.NewMatcher()
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, "", err
+	}
+
+	top := abs
+	var git os.FileInfo
+	for {
+		if git, err = os.Lstat(filepath.Join(top, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(top)
+		if parent == top {
+			return ignore, "", nil
+		}
+		top = parent
+	}
+
+	// A linked worktree or submodule has a .git file in place of the
+	// directory, so there is no exclude file to read from it.
+	if git.IsDir() {
+		if err := readIgnoreFile(ignore, filepath.Join(top, ".git", "info", "exclude"), ""); err != nil {
+			return nil, "", err
+		}
+	}
+
+	rel, err := filepath.Rel(top, abs)
+	if err != nil {
+		return nil, "", err
+	}
+	if rel == "." {
+		return ignore, "", nil
+	}
+	prefix := filepath.ToSlash(rel)
+
+	// The .gitignore file of the root itself is read during the walk.
+	dir, base := top, ""
+	for _, segment := range strings.Split(prefix, "/") {
+		if err := addIgnoreFile(ignore, dir, base); err != nil {
+			return nil, "", err
+		}
+		dir, base = filepath.Join(dir, segment), within(base, segment)
+	}
+	return ignore, prefix, nil
+}
+
+// addIgnoreFile reads the .gitignore file found in dir, if there is one,
+// into the Matcher.
+func addIgnoreFile(ignore *gitignore.Matcher, dir string, rel string) error {
+	return readIgnoreFile(ignore, filepath.Join(dir, ".gitignore"), rel)
+}
+
+// readIgnoreFile reads the patterns of the ignore file at path, if there is
+// one, into the Matcher as though it had been found in the rel directory.
+func readIgnoreFile(ignore *gitignore.Matcher, path string, rel string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return ignore.Add(rel, f)
+}
+
+// within joins the slash separated path rel onto the directory dir, where an
+// empty dir is the top of the repository and a rel of "." is dir itself.
+func within(dir string, rel string) string {
+	switch {
+	case dir == "":
+		return rel
+	case rel == ".":
+		return dir
+	}
+	return dir + "/" + rel
+}
+
+// matchAny reports whether the slash separated path matches one of the globs.
+func matchAny(globs []string, rel string) bool {
+	for _, glob := range globs {
+		if gitignore.Match(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
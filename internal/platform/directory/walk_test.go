@@ -0,0 +1,137 @@
+package directory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMarkdownFiles(t *testing.T) {
+	root := t.TempDir()
+	tree := map[string]string{
+		".gitignore":               "build/\n*.draft.md\n",
+		"README.md":                "# Title",
+		"notes.draft.md":           "# Draft",
+		"guide.markdown":           "# Guide",
+		"notes.txt":                "Notes",
+		".git/HEAD.md":             "# Git",
+		"build/output.md":          "# Build",
+		"docs/.gitignore":          "private.md\n!keep.draft.md\n",
+		"docs/index.md":            "# Index",
+		"docs/private.md":          "# Private",
+		"docs/keep.draft.md":       "# Kept",
+		"docs/api/private.md":      "# Nested Private",
+		"docs/api/reference.md":    "# Reference",
+		"vendor/lib/README.md":     "# Vendored",
+		"examples/example.md":      "# Example",
+		"examples/example.txt":     "Example",
+		"examples/nested/again.md": "# Again",
+	}
+	for name, contents := range tree {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("\t%s\tShould be able to make the directory : %v", failure, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("\t%s\tShould be able to write the file : %v", failure, err)
+		}
+	}
+
+	tt := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{
+			"no globs are given", nil, nil,
+			[]string{"README.md", "docs/api/reference.md", "docs/index.md", "docs/keep.draft.md", "examples/example.md", "examples/nested/again.md", "guide.markdown", "vendor/lib/README.md"},
+		},
+		{
+			"a directory is excluded", nil, []string{"vendor"},
+			[]string{"README.md", "docs/api/reference.md", "docs/index.md", "docs/keep.draft.md", "examples/example.md", "examples/nested/again.md", "guide.markdown"},
+		},
+		{
+			"text files are included and markdown files excluded", []string{"*.txt"}, []string{"examples/*.md"},
+			[]string{"examples/example.txt", "notes.txt"},
+		},
+		{
+			"only the files of a directory are included", []string{"examples/**"}, []string{"*.txt"},
+			[]string{"examples/example.md", "examples/nested/again.md"},
+		},
+	}
+
+	t.Log("Given the need to find the markdown files within a directory")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen %s", testID, test.name)
+		{
+			files, err := MarkdownFiles(root, test.include, test.exclude)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to walk the directory : %v", failure, testID, err)
+			}
+			var got []string
+			for _, file := range files {
+				rel, err := filepath.Rel(root, file)
+				if err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould return paths within the directory : %v", failure, testID, err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if reflect.DeepEqual(got, test.want) {
+				t.Logf("\t%s\tTest %d:\tShould skip .git and the files ignored by each .gitignore or excluded.", success, testID)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould skip .git and the files ignored by each .gitignore or excluded : %v", failure, testID, got)
+			}
+		}
+	}
+}
+
+func TestMarkdownFilesWithinRepository(t *testing.T) {
+	top := t.TempDir()
+	tree := map[string]string{
+		".git/info/exclude":           "*.local.md\n",
+		".gitignore":                  "secret.md\n/docs/guide/build/\n",
+		"docs/.gitignore":             "old.md\n",
+		"docs/guide/index.md":         "# Index",
+		"docs/guide/secret.md":        "# Secret",
+		"docs/guide/old.md":           "# Old",
+		"docs/guide/notes.local.md":   "# Notes",
+		"docs/guide/build/output.md":  "# Build",
+		"docs/guide/api/reference.md": "# Reference",
+	}
+	for name, contents := range tree {
+		path := filepath.Join(top, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("\t%s\tShould be able to make the directory : %v", failure, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("\t%s\tShould be able to write the file : %v", failure, err)
+		}
+	}
+	root := filepath.Join(top, "docs", "guide")
+	want := []string{"api/reference.md", "index.md"}
+
+	t.Log("Given the need to find the markdown files within a directory of a repository")
+	t.Logf("Test 0:\tWhen the repository ignores files from above the directory")
+	{
+		files, err := MarkdownFiles(root, nil, nil)
+		if err != nil {
+			t.Fatalf("\t%s\tTest 0:\tShould be able to walk the directory : %v", failure, err)
+		}
+		var got []string
+		for _, file := range files {
+			rel, err := filepath.Rel(root, file)
+			if err != nil {
+				t.Fatalf("\t%s\tTest 0:\tShould return paths within the directory : %v", failure, err)
+			}
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if reflect.DeepEqual(got, want) {
+			t.Logf("\t%s\tTest 0:\tShould skip the files ignored by .git/info/exclude and the .gitignore files above it.", success)
+		} else {
+			t.Errorf("\t%s\tTest 0:\tShould skip the files ignored by .git/info/exclude and the .gitignore files above it : %v", failure, got)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package gitignore implements the subset of the gitignore pattern
+// format that is needed to decide which files of a working tree
+// should be scanned for markdown links.
+package gitignore
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"strings"
+)
+
+// pattern holds a single line of a .gitignore file once it has
+// been parsed.
+type pattern struct {
+	// base is the slash separated directory, relative to the root of
+	// the walk, that contained the .gitignore file.
+	base string
+	// segments are the slash separated parts of the glob.
+	segments []string
+	// negate is set for patterns starting with "!", which re-include
+	// a path that has been excluded by an earlier pattern.
+	negate bool
+	// dirOnly is set for patterns ending in "/", which only match directories.
+	dirOnly bool
+	// anchored is set for patterns containing a "/", which are matched
+	// against the full path rather than the base name.
+	anchored bool
+}
+
+// Matcher holds every pattern that has been read from the .gitignore
+// files found while walking a directory tree.
+type Matcher struct {
+	patterns []pattern
+}
+
+// NewMatcher is a wrapper for the creation of an empty Matcher type.
+func NewMatcher() *Matcher {
+	return &Matcher{}
+}
+
+// Add reads the patterns from a .gitignore file found within the base
+// directory. The base directory is slash separated and relative to the
+// root of the walk, with "" representing the root itself.
+func (m *Matcher) Add(base string, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := pattern{base: base}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		p.segments = strings.Split(line, "/")
+		m.patterns = append(m.patterns, p)
+	}
+	return scanner.Err()
+}
+
+// Ignored reports whether the slash separated path, relative to the root
+// of the walk, is excluded by the patterns held within the Matcher.
+// As with git, the last matching pattern decides the outcome.
+func (m *Matcher) Ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		rel := name
+		if p.base != "" {
+			if !strings.HasPrefix(name, p.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(name, p.base+"/")
+		}
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.match(rel) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// match compares the pattern against a path relative to the directory
+// holding the .gitignore file.
+func (p pattern) match(rel string) bool {
+	if !p.anchored {
+		return matchSegments(p.segments, []string{path.Base(rel)})
+	}
+	return matchSegments(p.segments, strings.Split(rel, "/"))
+}
+
+// Match reports whether a slash separated path matches a glob written
+// in the gitignore style. A glob without a "/" is compared against the
+// base name of the path, while any other glob is compared against the
+// full path and may use "**" to match any number of directories.
+func Match(glob string, name string) bool {
+	glob = strings.TrimPrefix(glob, "./")
+	if !strings.Contains(glob, "/") {
+		return matchSegments([]string{glob}, []string{path.Base(name)})
+	}
+	return matchSegments(strings.Split(strings.TrimPrefix(glob, "/"), "/"), strings.Split(name, "/"))
+}
+
+// matchSegments compares each segment of a glob with each segment of a
+// path, expanding "**" to zero or more path segments.
+func matchSegments(glob []string, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		ok, err := path.Match(glob[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+		glob = glob[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
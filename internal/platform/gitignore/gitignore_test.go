@@ -0,0 +1,84 @@
+package gitignore
+
+import (
+	"strings"
+	"testing"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestIgnored(t *testing.T) {
+	ignore := `
+# build output
+/build/
+*.tmp
+!keep.tmp
+vendor/
+docs/**/draft-*.md
+`
+	m := NewMatcher()
+	if err := m.Add("", strings.NewReader(ignore)); err != nil {
+		t.Fatalf("\t%s\tShould be able to read the patterns: %v", failure, err)
+	}
+	if err := m.Add("sub", strings.NewReader("local.md\n")); err != nil {
+		t.Fatalf("\t%s\tShould be able to read the patterns: %v", failure, err)
+	}
+
+	tt := []struct {
+		name    string
+		isDir   bool
+		ignored bool
+	}{
+		{"build", true, true},
+		{"build", false, false},
+		{"docs/build", true, false},
+		{"notes.tmp", false, true},
+		{"a/b/notes.tmp", false, true},
+		{"keep.tmp", false, false},
+		{"third_party/vendor", true, true},
+		{"docs/draft-intro.md", false, true},
+		{"docs/guide/draft-intro.md", false, true},
+		{"docs/guide/intro.md", false, false},
+		{"sub/local.md", false, true},
+		{"local.md", false, false},
+	}
+
+	t.Log("Given the need to exclude paths listed within a .gitignore file")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen checking if %q is ignored", testID, test.name)
+		if m.Ignored(test.name, test.isDir) == test.ignored {
+			t.Logf("\t%s\tTest %d:\tShould report ignored as %v", success, testID, test.ignored)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould report ignored as %v", failure, testID, test.ignored)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tt := []struct {
+		glob  string
+		name  string
+		match bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide/README.md", true},
+		{"*.md", "docs/guide/README.txt", false},
+		{"docs/*.md", "docs/README.md", true},
+		{"docs/*.md", "docs/guide/README.md", false},
+		{"docs/**/*.md", "docs/guide/README.md", true},
+		{"docs/**/*.md", "docs/README.md", true},
+		{"./docs/**", "docs/guide/README.md", true},
+		{"CHANGELOG.md", "docs/CHANGELOG.md", true},
+	}
+
+	t.Log("Given the need to select files with include and exclude globs")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen matching %q against %q", testID, test.glob, test.name)
+		if Match(test.glob, test.name) == test.match {
+			t.Logf("\t%s\tTest %d:\tShould report a match as %v", success, testID, test.match)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould report a match as %v", failure, testID, test.match)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package progress displays how far a run has got while its links are being
+// extracted and checked, either as a bar redrawn in place on a terminal or
+// as a line logged every so often otherwise.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// Default intervals at which the Progress is written.
+const (
+	DefaultBarInterval = 200 * time.Millisecond
+	DefaultLogInterval = 10 * time.Second
+)
+
+// barWidth is the number of characters between the brackets of the bar.
+const barWidth = 20
+
+// Progress counts the files parsed and the links queued, checked and found
+// broken during a run. The methods of a nil Progress do nothing, so that a
+// run without a progress display need not check for one.
+type Progress struct {
+	// Interval is how often the Progress is written once started.
+	Interval time.Duration
+
+	w io.Writer
+	// bar redraws a single line in place, rather than logging a new line.
+	bar bool
+	// now is replaced within tests.
+	now   func() time.Time
+	start time.Time
+	stop  chan struct{}
+	done  chan struct{}
+
+	// mu guards the counts, and the writes made to w.
+	mu        sync.Mutex
+	files     int
+	queued    int
+	checked   int
+	failed    int
+	extracted bool
+}
+
+// New returns a Progress that writes to w, as a bar redrawn in place when
+// bar is set, or otherwise as a new line each time.
+func New(w io.Writer, bar bool) *Progress {
+	interval := DefaultLogInterval
+	if bar {
+		interval = DefaultBarInterval
+	}
+	return &Progress{
+		Interval: interval,
+		w:        w,
+		bar:      bar,
+		now:      time.Now,
+	}
+}
+
+// IsTerminal reports whether the file is a terminal, on which a bar can be
+// redrawn in place.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start writes the Progress every Interval until Stop is called.
+func (p *Progress) Start() {
+	if p == nil {
+		return
+	}
+	p.start = p.now()
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.write()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes the Progress a final time, leaving the bar on its own line.
+func (p *Progress) Stop() {
+	if p == nil || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.write()
+	if p.bar {
+		p.mu.Lock()
+		fmt.Fprintln(p.w)
+		p.mu.Unlock()
+	}
+}
+
+// Parsed counts n more files whose links have been extracted.
+func (p *Progress) Parsed(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.files += n
+	p.mu.Unlock()
+}
+
+// Links counts each link received from links as queued, passing it on
+// through the returned channel, which is closed once links has been. Every
+// link has been extracted once links is closed, after which the time left
+// can be estimated.
+func (p *Progress) Links(links <-chan markdown.Link) <-chan markdown.Link {
+	if p == nil {
+		return links
+	}
+	out := make(chan markdown.Link)
+	go func() {
+		defer close(out)
+		for link := range links {
+			p.mu.Lock()
+			p.queued++
+			p.mu.Unlock()
+			out <- link
+		}
+		p.mu.Lock()
+		p.extracted = true
+		p.mu.Unlock()
+	}()
+	return out
+}
+
+// Results counts each result received from results as checked, and as
+// failed when it is broken, passing it on through the returned channel,
+// which is closed once results has been.
+func (p *Progress) Results(results <-chan urlcheck.Result) <-chan urlcheck.Result {
+	if p == nil {
+		return results
+	}
+	out := make(chan urlcheck.Result)
+	go func() {
+		defer close(out)
+		for result := range results {
+			p.mu.Lock()
+			p.checked++
+			if result.Broken() {
+				p.failed++
+			}
+			p.mu.Unlock()
+			out <- result
+		}
+	}()
+	return out
+}
+
+// Write writes b to the writer of the Progress, clearing the bar first so
+// that other messages are not written over it. The bar is redrawn when the
+// Progress is next written.
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bar {
+		if _, err := io.WriteString(p.w, "\r\x1b[K"); err != nil {
+			return 0, err
+		}
+	}
+	return p.w.Write(b)
+}
+
+// String summarises the Progress, e.g., "12 files parsed, 120 of 300 links
+// checked, 3 failed, 25.0 links/s, 7s left". The number of links queued is
+// followed by a "+" until every link has been extracted, and the time left
+// is only estimated from then on.
+func (p *Progress) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.summary()
+}
+
+// summary is String, with mu held.
+func (p *Progress) summary() string {
+	queued := fmt.Sprint(p.queued)
+	if !p.extracted {
+		queued += "+"
+	}
+	s := fmt.Sprintf("%d files parsed, %d of %s links checked, %d failed", p.files, p.checked, queued, p.failed)
+
+	elapsed := p.now().Sub(p.start).Seconds()
+	if p.start.IsZero() || elapsed <= 0 {
+		return s
+	}
+	rate := float64(p.checked) / elapsed
+	s += fmt.Sprintf(", %.1f links/s", rate)
+	if p.extracted && rate > 0 {
+		left := time.Duration(float64(p.queued-p.checked) / rate * float64(time.Second))
+		s += fmt.Sprintf(", %v left", left.Round(time.Second))
+	}
+	return s
+}
+
+// write writes the Progress once, redrawing the bar in place or logging a
+// new line.
+func (p *Progress) write() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.bar {
+		fmt.Fprintf(p.w, "[+] Progress: %s\n", p.summary())
+		return
+	}
+
+	filled := 0
+	if p.queued > 0 {
+		filled = barWidth * p.checked / p.queued
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	// \r returns to the start of the line, and \x1b[K clears whatever is
+	// left of the previous, longer, line.
+	fmt.Fprintf(p.w, "\r[%s] %s\x1b[K", bar, p.summary())
+}
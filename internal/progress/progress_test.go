@@ -0,0 +1,110 @@
+package progress
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(&buf, false)
+	p.Interval = time.Hour
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return start }
+	p.Start()
+
+	links := make(chan markdown.Link, 4)
+	queued := p.Links(links)
+	for i := 0; i < 4; i++ {
+		links <- markdown.Link{URL: "https://example.com"}
+	}
+	p.Parsed(2)
+
+	results := make(chan urlcheck.Result, 2)
+	checked := p.Results(results)
+	for i := 0; i < 4; i++ {
+		<-queued
+	}
+	results <- urlcheck.Result{StatusCode: http.StatusOK}
+	results <- urlcheck.Result{StatusCode: http.StatusNotFound}
+	<-checked
+	<-checked
+	p.now = func() time.Time { return start.Add(2 * time.Second) }
+
+	t.Log("Given the need to show how far a run has got")
+	{
+		want := "2 files parsed, 2 of 4+ links checked, 1 failed, 1.0 links/s"
+		if got := p.String(); got == want {
+			t.Logf("\t%s\tShould mark the links queued as growing while links are extracted.", success)
+		} else {
+			t.Errorf("\t%s\tShould mark the links queued as growing while links are extracted : %q", failure, got)
+		}
+
+		close(links)
+		if _, ok := <-queued; !ok {
+			t.Logf("\t%s\tShould pass on every link before closing.", success)
+		} else {
+			t.Errorf("\t%s\tShould pass on every link before closing.", failure)
+		}
+		want = "2 files parsed, 2 of 4 links checked, 1 failed, 1.0 links/s, 2s left"
+		if got := p.String(); got == want {
+			t.Logf("\t%s\tShould estimate the time left once every link is extracted.", success)
+		} else {
+			t.Errorf("\t%s\tShould estimate the time left once every link is extracted : %q", failure, got)
+		}
+
+		p.Stop()
+		if got := buf.String(); got == "[+] Progress: "+want+"\n" {
+			t.Logf("\t%s\tShould log the progress a final time when stopped.", success)
+		} else {
+			t.Errorf("\t%s\tShould log the progress a final time when stopped : %q", failure, got)
+		}
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(&buf, true)
+	p.Interval = time.Hour
+	p.Start()
+	p.queued, p.checked = 4, 1
+	p.Stop()
+
+	t.Log("Given a progress display on a terminal")
+	{
+		got := buf.String()
+		if strings.HasPrefix(got, "\r[=====               ] 0 files parsed, 1 of 4+ links checked") {
+			t.Logf("\t%s\tShould redraw a bar filled by the links checked.", success)
+		} else {
+			t.Errorf("\t%s\tShould redraw a bar filled by the links checked : %q", failure, got)
+		}
+		if strings.HasSuffix(got, "\x1b[K\n") {
+			t.Logf("\t%s\tShould leave the bar on its own line when stopped.", success)
+		} else {
+			t.Errorf("\t%s\tShould leave the bar on its own line when stopped : %q", failure, got)
+		}
+	}
+
+	t.Log("Given no progress display")
+	{
+		var p *Progress
+		links := make(chan markdown.Link)
+		p.Start()
+		p.Parsed(1)
+		p.Stop()
+		if p.Links(links) == (<-chan markdown.Link)(links) {
+			t.Logf("\t%s\tShould pass the links straight through.", success)
+		} else {
+			t.Errorf("\t%s\tShould pass the links straight through.", failure)
+		}
+	}
+}
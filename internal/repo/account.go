@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"path"
+
+	"github.com/google/go-github/v33/github"
+)
+
+// RepositoryFilter selects which repositories of an account are scanned.
+type RepositoryFilter struct {
+	// Archived includes archived repositories, which are skipped by default.
+	Archived bool
+	// Forks includes forked repositories, which are skipped by default.
+	Forks bool
+	// Topics only includes repositories tagged with every one of the topics.
+	Topics []string
+	// Names only includes repositories whose name matches one of the globs,
+	// e.g., "m-*".
+	Names []string
+}
+
+// Match reports whether the repository is selected by the filter.
+func (f RepositoryFilter) Match(repository *github.Repository) bool {
+	if repository.GetArchived() && !f.Archived {
+		return false
+	}
+	if repository.GetFork() && !f.Forks {
+		return false
+	}
+
+	topics := make(map[string]bool)
+	for _, topic := range repository.Topics {
+		topics[topic] = true
+	}
+	for _, topic := range f.Topics {
+		if !topics[topic] {
+			return false
+		}
+	}
+
+	if len(f.Names) == 0 {
+		return true
+	}
+	for _, name := range f.Names {
+		if ok, _ := path.Match(name, repository.GetName()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ListRepositories lists the repositories owned by an organisation or user
+// account that are selected by the filter. A Repository is returned for each,
+// sharing the connection to GitHub that has been used to list them.
+func (r *Repository) ListRepositories(ctx context.Context, account string, filter RepositoryFilter) ([]*Repository, error) {
+	user, _, err := r.client.Users.Get(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []*github.Repository
+	if user.GetType() == "Organization" {
+		opts := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			page, resp, err := r.client.Repositories.ListByOrg(ctx, account, opts)
+			if err != nil {
+				return nil, err
+			}
+			repositories = append(repositories, page...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	} else {
+		opts := &github.RepositoryListOptions{Type: "owner", ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			page, resp, err := r.client.Repositories.List(ctx, account, opts)
+			if err != nil {
+				return nil, err
+			}
+			repositories = append(repositories, page...)
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+
+	var selected []*Repository
+	for _, repository := range repositories {
+		if !filter.Match(repository) {
+			continue
+		}
+		selected = append(selected, r.sibling(repository.GetOwner().GetLogin(), repository.GetName()))
+	}
+	return selected, nil
+}
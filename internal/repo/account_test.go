@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestListRepositories(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/my-org", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "my-org", "type": "Organization"}`)
+	})
+	mux.HandleFunc("/orgs/my-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"name": "service-a", "owner": {"login": "my-org"}, "topics": ["docs", "go"]},
+			{"name": "service-b", "owner": {"login": "my-org"}, "topics": ["go"]},
+			{"name": "service-c", "owner": {"login": "my-org"}, "topics": ["docs"], "archived": true},
+			{"name": "service-d", "owner": {"login": "my-org"}, "topics": ["docs"], "fork": true},
+			{"name": "website", "owner": {"login": "my-org"}, "topics": ["docs"]}
+		]`)
+	})
+	r := newFakeGithub(t, mux)
+
+	tt := []struct {
+		filter RepositoryFilter
+		names  string
+	}{
+		{RepositoryFilter{}, "[service-a service-b website]"},
+		{RepositoryFilter{Topics: []string{"docs"}}, "[service-a website]"},
+		{RepositoryFilter{Topics: []string{"docs"}, Archived: true, Forks: true}, "[service-a service-c service-d website]"},
+		{RepositoryFilter{Names: []string{"service-*"}}, "[service-a service-b]"},
+	}
+
+	t.Log("Given the need to scan the repositories of an organisation")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen listing repositories with the filter %+v", testID, test.filter)
+		repositories, err := r.ListRepositories(context.Background(), "my-org", test.filter)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to list the repositories : %v", failure, testID, err)
+		}
+		var names []string
+		for _, repository := range repositories {
+			names = append(names, repository.RepoName)
+		}
+		if fmt.Sprint(names) == test.names {
+			t.Logf("\t%s\tTest %d:\tShould select %s", success, testID, test.names)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould select %s : %v", failure, testID, test.names, names)
+		}
+	}
+}
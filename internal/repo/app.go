@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v33/github"
+	"golang.org/x/oauth2"
+)
+
+// App holds what is needed to authenticate as an installation of a GitHub
+// App, rather than as the owner of a personal access token.
+type App struct {
+	// ID is the ID of the GitHub App.
+	ID int64
+	// PrivateKey is the private key generated for the GitHub App.
+	PrivateKey *rsa.PrivateKey
+	// InstallationID is the ID of the installation to authenticate as. When
+	// zero, the installation on the repository is looked up, or on the
+	// organisation or user account when no repository has been named.
+	InstallationID int64
+}
+
+// ParsePrivateKey parses the PEM encoded private key of a GitHub App, in
+// either the PKCS #1 form GitHub generates or the PKCS #8 form.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// UseApp authenticates the Repository as an installation of the GitHub App
+// rather than with a personal access token. Installation tokens are created,
+// and refreshed before they expire, as they are needed. This must be called
+// before NewGithubConnection.
+func (r *Repository) UseApp(app App) {
+	r.app = &app
+}
+
+// appTokenSource creates the installation tokens of a GitHub App.
+type appTokenSource struct {
+	app   App
+	owner string
+	repo  string
+	// client authenticates with a JSON Web Token signed by the private key
+	// of the GitHub App.
+	client *github.Client
+}
+
+// newAppTokenSource is a wrapper for the creation of an appTokenSource,
+// whose requests are sent through the transport to the API at the base URL,
+// or to github.com when it is nil.
+func newAppTokenSource(app App, r *Repository, transport http.RoundTripper) *appTokenSource {
+	client := github.NewClient(&http.Client{Transport: &jwtTransport{app: app, base: transport, now: time.Now}})
+	if r.baseURL != nil {
+		client.BaseURL = r.baseURL
+		client.UploadURL = r.uploadURL
+	}
+	return &appTokenSource{app: app, owner: r.Owner, repo: r.RepoName, client: client}
+}
+
+// Token creates a new installation token, looking up the installation the
+// first time when no installation ID has been given.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+	if s.app.InstallationID == 0 {
+		id, err := s.installation(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.app.InstallationID = id
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.app.InstallationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating an installation token for GitHub App %d: %w", s.app.ID, err)
+	}
+	return &oauth2.Token{AccessToken: token.GetToken(), Expiry: token.GetExpiresAt()}, nil
+}
+
+// installation looks up the ID of the installation of the GitHub App on the
+// repository or, when no repository has been named, on the organisation or
+// user account that owns the repositories.
+func (s *appTokenSource) installation(ctx context.Context) (int64, error) {
+	switch {
+	case s.owner == "":
+		return 0, fmt.Errorf("the installation of GitHub App %d cannot be found without an owner, set its installation ID", s.app.ID)
+	case s.repo != "":
+		installation, _, err := s.client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repo)
+		if err != nil {
+			return 0, fmt.Errorf("finding the installation of GitHub App %d on %s/%s: %w", s.app.ID, s.owner, s.repo, err)
+		}
+		return installation.GetID(), nil
+	}
+
+	installation, resp, err := s.client.Apps.FindOrganizationInstallation(ctx, s.owner)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		installation, _, err = s.client.Apps.FindUserInstallation(ctx, s.owner)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("finding the installation of GitHub App %d on %s: %w", s.app.ID, s.owner, err)
+	}
+	return installation.GetID(), nil
+}
+
+// jwtTransport authenticates each request as the GitHub App itself, with a
+// short lived JSON Web Token signed by its private key.
+type jwtTransport struct {
+	app  App
+	base http.RoundTripper
+	now  func() time.Time
+}
+
+func (t *jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.app.jwt(t.now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// jwt creates a JSON Web Token identifying the GitHub App, which is issued a
+// minute in the past to allow for clock drift, and expires well within the
+// ten minutes GitHub allows.
+func (app App) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": app.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, app.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing JSON Web Token: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,160 @@
+package repo
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v33/github"
+)
+
+func TestUseApp(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to generate a private key : %v", failure, err)
+	}
+
+	// verify checks that the request is authenticated as the GitHub App.
+	verify := func(r *http.Request) error {
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		if len(parts) != 3 {
+			return fmt.Errorf("expected a JSON Web Token : %q", r.Header.Get("Authorization"))
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return err
+		}
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return err
+		}
+		var c struct {
+			Iss int64 `json:"iss"`
+		}
+		if err := json.Unmarshal(claims, &c); err != nil || c.Iss != 7 {
+			return fmt.Errorf("expected to be issued by the GitHub App : %s", claims)
+		}
+		return nil
+	}
+
+	var errs []error
+	var created int
+	var authorization []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/installation", func(w http.ResponseWriter, r *http.Request) {
+		if err := verify(r); err != nil {
+			errs = append(errs, err)
+		}
+		fmt.Fprint(w, `{"id": 42}`)
+	})
+	mux.HandleFunc("/api/v3/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if err := verify(r); err != nil {
+			errs = append(errs, err)
+		}
+		created++
+		// The token expires within the time oauth2 refreshes tokens ahead
+		// of their expiry, so that every request needs a new one.
+		fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": "%s"}`, created, time.Now().Add(5*time.Second).Format(time.RFC3339))
+	})
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `[]`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Log("Given the need to authenticate as a GitHub App")
+	{
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		parsed, err := ParsePrivateKey(data)
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to parse the private key : %v", failure, err)
+		}
+		t.Logf("\t%s\tShould be able to parse the private key.", success)
+
+		r := NewRepository("jwhitt3r", "m-check", "")
+		if err := r.UseEnterprise(srv.URL, ""); err != nil {
+			t.Fatalf("\t%s\tShould be able to use the server URL : %v", failure, err)
+		}
+		r.UseApp(App{ID: 7, PrivateKey: parsed})
+		r.NewGithubConnection()
+
+		for i := 0; i < 2; i++ {
+			if _, err := r.PullRequestFiles(context.Background(), 1); err != nil {
+				t.Fatalf("\t%s\tShould be able to make requests as the installation : %v", failure, err)
+			}
+		}
+		if len(errs) == 0 {
+			t.Logf("\t%s\tShould sign a JSON Web Token with the private key.", success)
+		} else {
+			t.Errorf("\t%s\tShould sign a JSON Web Token with the private key : %v", failure, errs)
+		}
+		if strings.Join(authorization, ",") == "Bearer ghs_1,Bearer ghs_2" {
+			t.Logf("\t%s\tShould refresh the installation token once it expires.", success)
+		} else {
+			t.Errorf("\t%s\tShould refresh the installation token once it expires : %v", failure, authorization)
+		}
+	}
+}
+
+func TestAppInstallation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/installation", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+	mux.HandleFunc("/api/v3/orgs/my-org/installation", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 2}`)
+	})
+	mux.HandleFunc("/api/v3/orgs/jwhitt3r/installation", http.NotFound)
+	mux.HandleFunc("/api/v3/users/jwhitt3r/installation", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 3}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tt := []struct {
+		owner string
+		repo  string
+		id    int64
+	}{
+		{"jwhitt3r", "m-check", 1},
+		{"my-org", "", 2},
+		{"jwhitt3r", "", 3},
+		{"", "", 0},
+	}
+
+	t.Log("Given the need to find the installation of a GitHub App")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the owner is %q and the repository is %q", testID, test.owner, test.repo)
+		{
+			r := NewRepository(test.owner, test.repo, "")
+			if err := r.UseEnterprise(srv.URL, ""); err != nil {
+				t.Fatalf("\t%s\tShould be able to use the server URL : %v", failure, err)
+			}
+			s := &appTokenSource{owner: r.Owner, repo: r.RepoName, client: github.NewClient(nil)}
+			s.client.BaseURL = r.baseURL
+			id, err := s.installation(context.Background())
+			if id == test.id && (err != nil) == (test.id == 0) {
+				t.Logf("\t%s\tTest %d:\tShould find installation %d.", success, testID, test.id)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould find installation %d : %d %v", failure, testID, test.id, id, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+)
+
+// Body is the markdown text of an issue, pull request, release or
+// discussion of the repository.
+type Body struct {
+	// URL is the address of the issue, pull request, release or discussion
+	// on the website, which is reported as where its links were found.
+	URL string
+	// Text is the markdown text of the body.
+	Text string
+}
+
+// BodyFilter selects which bodies of the repository are gathered.
+type BodyFilter struct {
+	// Issues gathers the bodies of issues.
+	Issues bool
+	// PullRequests gathers the bodies of pull requests.
+	PullRequests bool
+	// Releases gathers the release notes of releases.
+	Releases bool
+	// Discussions gathers the bodies of discussions.
+	Discussions bool
+	// Labels only includes issues and pull requests with every one of the
+	// labels.
+	Labels []string
+	// State only includes issues and pull requests in the state, one of
+	// open, closed or all. By default open.
+	State string
+}
+
+// Bodies gathers the markdown bodies selected by the filter. The tracking
+// issue kept by SyncTrackingIssue is skipped, as it only lists links that
+// have already been found broken.
+func (r *Repository) Bodies(ctx context.Context, filter BodyFilter) ([]Body, error) {
+	var bodies []Body
+	if filter.Issues || filter.PullRequests {
+		found, err := r.issueBodies(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("listing issues: %w", err)
+		}
+		bodies = append(bodies, found...)
+	}
+	if filter.Releases {
+		found, err := r.releaseBodies(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing releases: %w", err)
+		}
+		bodies = append(bodies, found...)
+	}
+	if filter.Discussions {
+		found, err := r.discussionBodies(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing discussions: %w", err)
+		}
+		bodies = append(bodies, found...)
+	}
+	return bodies, nil
+}
+
+// issueBodies lists the bodies of the issues and pull requests selected by
+// the filter, both of which are listed through the issues API.
+func (r *Repository) issueBodies(ctx context.Context, filter BodyFilter) ([]Body, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       filter.State,
+		Labels:      filter.Labels,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var bodies []Body
+	for {
+		issues, resp, err := r.client.Issues.ListByRepo(ctx, r.Owner, r.RepoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if issue.IsPullRequest() && !filter.PullRequests || !issue.IsPullRequest() && !filter.Issues {
+				continue
+			}
+			if issue.GetBody() == "" || strings.Contains(issue.GetBody(), issueMarker) {
+				continue
+			}
+			bodies = append(bodies, Body{URL: issue.GetHTMLURL(), Text: issue.GetBody()})
+		}
+
+		if resp.NextPage == 0 {
+			return bodies, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// releaseBodies lists the release notes of every release.
+func (r *Repository) releaseBodies(ctx context.Context) ([]Body, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var bodies []Body
+	for {
+		releases, resp, err := r.client.Repositories.ListReleases(ctx, r.Owner, r.RepoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if release.GetBody() != "" {
+				bodies = append(bodies, Body{URL: release.GetHTMLURL(), Text: release.GetBody()})
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return bodies, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// discussionsQuery lists a page of the discussions of a repository, which
+// are only available through the GraphQL API.
+const discussionsQuery = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 100, after: $cursor) {
+      nodes { url body }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// discussionBodies lists the bodies of every discussion.
+func (r *Repository) discussionBodies(ctx context.Context) ([]Body, error) {
+	var bodies []Body
+	var cursor *string
+	for {
+		var page struct {
+			Data struct {
+				Repository struct {
+					Discussions struct {
+						Nodes []struct {
+							URL  string `json:"url"`
+							Body string `json:"body"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"discussions"`
+				} `json:"repository"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		// The GraphQL API sits beside the REST API, at /graphql on
+		// github.com and at /api/graphql on GitHub Enterprise Server.
+		req, err := r.client.NewRequest("POST", "../graphql", map[string]interface{}{
+			"query":     discussionsQuery,
+			"variables": map[string]interface{}{"owner": r.Owner, "name": r.RepoName, "cursor": cursor},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.client.Do(ctx, req, &page); err != nil {
+			return nil, err
+		}
+		if len(page.Errors) > 0 {
+			return nil, fmt.Errorf("GraphQL: %s", page.Errors[0].Message)
+		}
+
+		discussions := page.Data.Repository.Discussions
+		for _, discussion := range discussions.Nodes {
+			if discussion.Body != "" {
+				bodies = append(bodies, Body{URL: discussion.URL, Text: discussion.Body})
+			}
+		}
+		if !discussions.PageInfo.HasNextPage {
+			return bodies, nil
+		}
+		cursor = &discussions.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestBodies(t *testing.T) {
+	tt := []struct {
+		filter BodyFilter
+		want   []string
+	}{
+		{BodyFilter{Issues: true}, []string{"https://github.com/jwhitt3r/m-check/issues/1"}},
+		{BodyFilter{PullRequests: true}, []string{"https://github.com/jwhitt3r/m-check/pull/2"}},
+		{BodyFilter{Releases: true}, []string{"https://github.com/jwhitt3r/m-check/releases/tag/v1.0.0"}},
+		{BodyFilter{Discussions: true}, []string{"https://github.com/jwhitt3r/m-check/discussions/4", "https://github.com/jwhitt3r/m-check/discussions/5"}},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/jwhitt3r/m-check/issues", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `[
+			{"number": 1, "html_url": "https://github.com/jwhitt3r/m-check/issues/1", "body": "See [docs](https://example.com)"},
+			{"number": 2, "html_url": "https://github.com/jwhitt3r/m-check/pull/2", "body": "Fixes #1", "pull_request": {"url": "https://api.github.com/repos/jwhitt3r/m-check/pulls/2"}},
+			{"number": 3, "html_url": "https://github.com/jwhitt3r/m-check/issues/3", "body": "`+issueMarker+`\n- https://example.com/broken"}
+		]`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/releases", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `[{"html_url": "https://github.com/jwhitt3r/m-check/releases/tag/v1.0.0", "body": "Notes"}, {"html_url": "https://github.com/jwhitt3r/m-check/releases/tag/v0.1.0", "body": ""}]`)
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var query struct {
+			Variables struct{ Cursor *string }
+		}
+		json.NewDecoder(req.Body).Decode(&query)
+		if query.Variables.Cursor == nil {
+			fmt.Fprint(w, `{"data": {"repository": {"discussions": {"nodes": [{"url": "https://github.com/jwhitt3r/m-check/discussions/4", "body": "Hello"}], "pageInfo": {"hasNextPage": true, "endCursor": "abc"}}}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"repository": {"discussions": {"nodes": [{"url": "https://github.com/jwhitt3r/m-check/discussions/5", "body": "World"}], "pageInfo": {"hasNextPage": false}}}}}`)
+	})
+	r := newFakeGithub(t, mux)
+
+	t.Log("Given the need to check the links of issues, pull requests, releases and discussions")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen gathering bodies with %+v", testID, test.filter)
+		{
+			bodies, err := r.Bodies(context.Background(), test.filter)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to gather the bodies : %v", failure, testID, err)
+			}
+			var got []string
+			for _, body := range bodies {
+				got = append(got, body.URL)
+			}
+			if fmt.Sprint(got) == fmt.Sprint(test.want) {
+				t.Logf("\t%s\tTest %d:\tShould gather %v", success, testID, test.want)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould gather %v : %v", failure, testID, test.want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v33/github"
+)
+
+// maxAnnotations is the number of annotations the GitHub API accepts
+// within a single request to create or update a check run.
+const maxAnnotations = 50
+
+// maxSummaryLength is the longest summary GitHub accepts within the output
+// of a check run.
+const maxSummaryLength = 65535
+
+// Annotation marks a single line of a file within a check run.
+type Annotation struct {
+	// Path is the slash separated path of the file, relative to the root
+	// of the repository.
+	Path string
+	// Line is the line number, within the file, to annotate.
+	Line int
+	// Title is a short summary of the annotation.
+	Title string
+	// Message describes the problem found on the line.
+	Message string
+}
+
+// CheckRun describes the outcome of a check to publish against a commit.
+type CheckRun struct {
+	// Name is the name shown for the check within the Checks tab.
+	Name string
+	// HeadSHA is the commit the check is published against.
+	HeadSHA string
+	// Success sets the conclusion of the check to success, or to
+	// failure when false.
+	Success bool
+	// Title and Summary are shown at the top of the check's output.
+	Title   string
+	Summary string
+	// Annotations mark the lines of files holding problems.
+	Annotations []Annotation
+}
+
+// PublishCheckRun creates a check run on the head commit, adding the
+// annotations in batches of the most the GitHub API accepts at once, and
+// then completes the check run with its conclusion. A summary longer than
+// GitHub accepts is cut short.
+func (r *Repository) PublishCheckRun(ctx context.Context, check CheckRun) (*github.CheckRun, error) {
+	run, _, err := r.client.Checks.CreateCheckRun(ctx, r.Owner, r.RepoName, github.CreateCheckRunOptions{
+		Name:    check.Name,
+		HeadSHA: check.HeadSHA,
+		Status:  github.String("in_progress"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := truncateBody(check.Summary, maxSummaryLength)
+	annotations := check.Annotations
+	for {
+		batch := annotations
+		if len(batch) > maxAnnotations {
+			batch = batch[:maxAnnotations]
+		}
+		annotations = annotations[len(batch):]
+
+		opts := github.UpdateCheckRunOptions{
+			Name: check.Name,
+			Output: &github.CheckRunOutput{
+				Title:       github.String(check.Title),
+				Summary:     github.String(summary),
+				Annotations: checkRunAnnotations(batch),
+			},
+		}
+		if len(annotations) == 0 {
+			conclusion := "failure"
+			if check.Success {
+				conclusion = "success"
+			}
+			opts.Status = github.String("completed")
+			opts.Conclusion = github.String(conclusion)
+			opts.CompletedAt = &github.Timestamp{Time: time.Now()}
+		}
+
+		run, _, err = r.client.Checks.UpdateCheckRun(ctx, r.Owner, r.RepoName, run.GetID(), opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(annotations) == 0 {
+			return run, nil
+		}
+	}
+}
+
+// checkRunAnnotations converts annotations into the form sent to the GitHub API.
+func checkRunAnnotations(annotations []Annotation) []*github.CheckRunAnnotation {
+	var converted []*github.CheckRunAnnotation
+	for _, annotation := range annotations {
+		converted = append(converted, &github.CheckRunAnnotation{
+			Path:            github.String(annotation.Path),
+			StartLine:       github.Int(annotation.Line),
+			EndLine:         github.Int(annotation.Line),
+			AnnotationLevel: github.String("failure"),
+			Title:           github.String(annotation.Title),
+			Message:         github.String(annotation.Message),
+		})
+	}
+	return converted
+}
@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPublishCheckRun(t *testing.T) {
+	tt := []struct {
+		annotations int
+		updates     []int
+		success     bool
+	}{
+		{0, []int{0}, true},
+		{50, []int{50}, false},
+		{120, []int{50, 50, 20}, false},
+	}
+
+	t.Log("Given the need to publish the results as a check run")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen publishing %d annotations", testID, test.annotations)
+		{
+			var created string
+			var updates []int
+			var status, conclusion string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/jwhitt3r/m-check/check-runs", func(w http.ResponseWriter, r *http.Request) {
+				var opts struct {
+					HeadSHA string `json:"head_sha"`
+				}
+				json.NewDecoder(r.Body).Decode(&opts)
+				created = opts.HeadSHA
+				fmt.Fprint(w, `{"id": 3}`)
+			})
+			mux.HandleFunc("/repos/jwhitt3r/m-check/check-runs/3", func(w http.ResponseWriter, r *http.Request) {
+				var opts struct {
+					Status     string
+					Conclusion string
+					Output     struct {
+						Annotations []json.RawMessage
+					}
+				}
+				json.NewDecoder(r.Body).Decode(&opts)
+				updates = append(updates, len(opts.Output.Annotations))
+				status, conclusion = opts.Status, opts.Conclusion
+				fmt.Fprint(w, `{"id": 3}`)
+			})
+			r := newFakeGithub(t, mux)
+
+			check := CheckRun{Name: "m-check", HeadSHA: "abc123", Success: test.success, Title: "m-check", Summary: "summary"}
+			for i := 0; i < test.annotations; i++ {
+				check.Annotations = append(check.Annotations, Annotation{Path: "docs/a.md", Line: i + 1, Message: "broken"})
+			}
+			if _, err := r.PublishCheckRun(context.Background(), check); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to publish the check run : %v", failure, testID, err)
+			}
+
+			if created == "abc123" {
+				t.Logf("\t%s\tTest %d:\tShould create the check run on the head commit", success, testID)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould create the check run on the head commit : %q", failure, testID, created)
+			}
+			if fmt.Sprint(updates) == fmt.Sprint(test.updates) {
+				t.Logf("\t%s\tTest %d:\tShould send the annotations in batches of %v", success, testID, test.updates)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould send the annotations in batches of %v : %v", failure, testID, test.updates, updates)
+			}
+			want := "failure"
+			if test.success {
+				want = "success"
+			}
+			if status == "completed" && conclusion == want {
+				t.Logf("\t%s\tTest %d:\tShould complete the check run with %s", success, testID, want)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould complete the check run with %s : %s %s", failure, testID, want, status, conclusion)
+			}
+		}
+	}
+}
+
+func TestPublishCheckRunSummary(t *testing.T) {
+	line := "| https://example.com/broken | README.md:1 | 404 |\n"
+	tt := []struct {
+		name      string
+		summary   string
+		truncated bool
+	}{
+		{"short", "1 broken.", false},
+		{"longer than GitHub accepts", strings.Repeat(line, maxSummaryLength/len(line)+10), true},
+	}
+
+	t.Log("Given the need to keep the summary of a check run within the length GitHub accepts")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the report is %s", testID, test.name)
+		{
+			var summary string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/jwhitt3r/m-check/check-runs", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"id": 3}`)
+			})
+			mux.HandleFunc("/repos/jwhitt3r/m-check/check-runs/3", func(w http.ResponseWriter, r *http.Request) {
+				var opts struct {
+					Output struct {
+						Summary string
+					}
+				}
+				json.NewDecoder(r.Body).Decode(&opts)
+				summary = opts.Output.Summary
+				fmt.Fprint(w, `{"id": 3}`)
+			})
+			r := newFakeGithub(t, mux)
+
+			check := CheckRun{Name: "m-check", HeadSHA: "abc123", Title: "m-check", Summary: test.summary}
+			if _, err := r.PublishCheckRun(context.Background(), check); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to publish the check run : %v", failure, testID, err)
+			}
+
+			switch {
+			case !test.truncated && summary == test.summary:
+				t.Logf("\t%s\tTest %d:\tShould send the summary as it is.", success, testID)
+			case test.truncated && len(summary) <= maxSummaryLength && strings.HasSuffix(summary, truncatedNote):
+				t.Logf("\t%s\tTest %d:\tShould cut the summary short, with a note.", success, testID)
+			default:
+				t.Errorf("\t%s\tTest %d:\tShould cut the summary short only when it is too long : %d", failure, testID, len(summary))
+			}
+		}
+	}
+}
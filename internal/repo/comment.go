@@ -0,0 +1,136 @@
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+)
+
+// summaryMarker is hidden within the body of the summary comment so that
+// the comment can be found, and updated in place, on later runs.
+const summaryMarker = "<!-- m-check:summary -->"
+
+// reviewMarker is hidden within the body of each review comment so that the
+// comments left by m-check can be told apart from those left by reviewers.
+const reviewMarker = "<!-- m-check:review -->"
+
+// ReviewComment is a comment left on a single line of a file that has been
+// changed by a pull request.
+type ReviewComment struct {
+	// Path is the slash separated path of the file, relative to the root
+	// of the repository.
+	Path string
+	// Line is the line number, within the changed file, to comment on.
+	Line int
+	// Body is the markdown text of the comment.
+	Body string
+}
+
+// UpsertSummaryComment publishes the body as a single comment on the pull
+// request. When a summary comment has already been left by a previous run
+// it is updated in place, rather than a new comment being created. A body
+// longer than GitHub accepts is cut short, with a note saying so.
+func (r *Repository) UpsertSummaryComment(ctx context.Context, number int, body string) (*github.IssueComment, error) {
+	comment := &github.IssueComment{Body: github.String(truncateBody(summaryMarker+"\n"+body, maxBodyLength))}
+
+	existing, err := r.findSummaryComment(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		comment, _, err = r.client.Issues.EditComment(ctx, r.Owner, r.RepoName, existing.GetID(), comment)
+		return comment, err
+	}
+
+	comment, _, err = r.client.Issues.CreateComment(ctx, r.Owner, r.RepoName, number, comment)
+	return comment, err
+}
+
+// findSummaryComment looks through the comments of the pull request for one
+// holding the summary marker, returning nil when there is none.
+func (r *Repository) findSummaryComment(ctx context.Context, number int) (*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := r.client.Issues.ListComments(ctx, r.Owner, r.RepoName, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), summaryMarker) {
+				return comment, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreateReviewComments leaves each comment on its line of the pull request
+// as a single review against the head commit. Comments that have already
+// been left by a previous run are skipped, and the number of comments that
+// have been created is returned.
+func (r *Repository) CreateReviewComments(ctx context.Context, number int, comments []ReviewComment) (int, error) {
+	existing, err := r.existingReviewComments(ctx, number)
+	if err != nil {
+		return 0, err
+	}
+
+	var drafts []*github.DraftReviewComment
+	for _, comment := range comments {
+		body := reviewMarker + "\n" + comment.Body
+		if existing[ReviewComment{Path: comment.Path, Line: comment.Line, Body: body}] {
+			continue
+		}
+		drafts = append(drafts, &github.DraftReviewComment{
+			Path: github.String(comment.Path),
+			Line: github.Int(comment.Line),
+			Side: github.String("RIGHT"),
+			Body: github.String(body),
+		})
+	}
+	if len(drafts) == 0 {
+		return 0, nil
+	}
+
+	pr, _, err := r.client.PullRequests.Get(ctx, r.Owner, r.RepoName, number)
+	if err != nil {
+		return 0, err
+	}
+
+	review := &github.PullRequestReviewRequest{
+		CommitID: github.String(pr.GetHead().GetSHA()),
+		Event:    github.String("COMMENT"),
+		Comments: drafts,
+	}
+	if _, _, err := r.client.PullRequests.CreateReview(ctx, r.Owner, r.RepoName, number, review); err != nil {
+		return 0, err
+	}
+	return len(drafts), nil
+}
+
+// existingReviewComments gathers the review comments previously left on the
+// pull request by m-check.
+func (r *Repository) existingReviewComments(ctx context.Context, number int) (map[ReviewComment]bool, error) {
+	existing := make(map[ReviewComment]bool)
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := r.client.PullRequests.ListComments(ctx, r.Owner, r.RepoName, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), reviewMarker) {
+				existing[ReviewComment{Path: comment.GetPath(), Line: comment.GetLine(), Body: comment.GetBody()}] = true
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return existing, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
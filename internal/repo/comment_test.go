@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newFakeGithub creates a Repository whose client talks to a local server
+// rather than to GitHub.
+func newFakeGithub(t *testing.T, mux *http.ServeMux) *Repository {
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	r := NewRepository("jwhitt3r", "m-check", "12345")
+	r.NewGithubConnection()
+	u, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to parse the server URL : %v", failure, err)
+	}
+	r.client.BaseURL = u
+	return r
+}
+
+func TestUpsertSummaryComment(t *testing.T) {
+	tt := []struct {
+		existing string
+		method   string
+	}{
+		{`[{"id": 1, "body": "LGTM"}]`, http.MethodPost},
+		{`[{"id": 1, "body": "LGTM"}, {"id": 7, "body": "` + summaryMarker + `\nold"}]`, http.MethodPatch},
+	}
+
+	t.Log("Given the need to keep a single summary comment on a pull request")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the pull request has the comments %s", testID, test.existing)
+		{
+			var method, path, body string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/jwhitt3r/m-check/issues/12/comments", func(w http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodGet {
+					fmt.Fprint(w, test.existing)
+					return
+				}
+				method, path = req.Method, req.URL.Path
+				var comment struct{ Body string }
+				json.NewDecoder(req.Body).Decode(&comment)
+				body = comment.Body
+				fmt.Fprint(w, `{"id": 8}`)
+			})
+			mux.HandleFunc("/repos/jwhitt3r/m-check/issues/comments/7", func(w http.ResponseWriter, req *http.Request) {
+				method, path = req.Method, req.URL.Path
+				var comment struct{ Body string }
+				json.NewDecoder(req.Body).Decode(&comment)
+				body = comment.Body
+				fmt.Fprint(w, `{"id": 7}`)
+			})
+			r := newFakeGithub(t, mux)
+
+			if _, err := r.UpsertSummaryComment(context.Background(), 12, "2 links checked, 1 broken."); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to publish the comment : %v", failure, testID, err)
+			}
+			if method == test.method {
+				t.Logf("\t%s\tTest %d:\tShould publish the comment with %s %s", success, testID, method, path)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould publish the comment with %s : %s", failure, testID, test.method, method)
+			}
+			if strings.HasPrefix(body, summaryMarker) {
+				t.Logf("\t%s\tTest %d:\tShould mark the comment so it can be found again", success, testID)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould mark the comment so it can be found again : %q", failure, testID, body)
+			}
+		}
+	}
+}
+
+func TestCreateReviewComments(t *testing.T) {
+	var review struct {
+		CommitID string `json:"commit_id"`
+		Comments []struct {
+			Path string
+			Line int
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/jwhitt3r/m-check/pulls/12/comments", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, `[{"path": "docs/a.md", "line": 3, "body": %q}]`, reviewMarker+"\nbroken")
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/pulls/12", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"number": 12, "head": {"sha": "abc123"}}`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/pulls/12/reviews", func(w http.ResponseWriter, req *http.Request) {
+		json.NewDecoder(req.Body).Decode(&review)
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+	r := newFakeGithub(t, mux)
+
+	t.Log("Given the need to comment on the lines of a pull request holding broken links")
+	{
+		comments := []ReviewComment{
+			{Path: "docs/a.md", Line: 3, Body: "broken"},
+			{Path: "docs/b.md", Line: 9, Body: "broken"},
+		}
+		created, err := r.CreateReviewComments(context.Background(), 12, comments)
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to create the review : %v", failure, err)
+		}
+		if created == 1 && len(review.Comments) == 1 && review.Comments[0].Path == "docs/b.md" {
+			t.Logf("\t%s\tShould only create the comment that has not been left before.", success)
+		} else {
+			t.Errorf("\t%s\tShould only create the comment that has not been left before : %+v", failure, review.Comments)
+		}
+		if review.CommitID == "abc123" {
+			t.Logf("\t%s\tShould review the head commit of the pull request.", success)
+		} else {
+			t.Errorf("\t%s\tShould review the head commit of the pull request : %q", failure, review.CommitID)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+)
+
+// maxCachedBody is the largest response body a ResponseCache keeps, so that
+// an unusually large listing does not bloat the file.
+const maxCachedBody = 1 << 20
+
+// maxCachedAge is how long a response is kept without being requested again,
+// after which it is dropped when the ResponseCache is saved.
+const maxCachedAge = 30 * 24 * time.Hour
+
+// cachedResponse is a response that has been saved along with its ETag, so
+// that it can be used again once the server reports it has not changed.
+type cachedResponse struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	// Used is when the response was last received or confirmed unchanged.
+	Used time.Time `json:"used"`
+}
+
+// ResponseCache remembers the JSON responses of the GitHub API along with
+// their ETags on disk between runs. A request made again is sent with the
+// ETag, and when GitHub reports the response has not changed, which does not
+// count against the rate limit, the saved response is used in its place. The
+// raw contents of files are never kept.
+type ResponseCache struct {
+	path string
+	// now is replaced within tests.
+	now func() time.Time
+	// mu guards entries.
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+// OpenResponseCache reads the responses saved at path, or starts an empty
+// cache when there is no file at path yet.
+func OpenResponseCache(path string) (*ResponseCache, error) {
+	c := ResponseCache{
+		path:    path,
+		now:     time.Now,
+		entries: make(map[string]cachedResponse),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("decoding response cache %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the responses back to the path, dropping those that have not
+// been used for a long time.
+func (c *ResponseCache) Save() error {
+	c.mu.Lock()
+	now := c.now()
+	for key, entry := range c.entries {
+		if now.Sub(entry.Used) >= maxCachedAge {
+			delete(c.entries, key)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	// The responses may describe private repositories, so only the owner
+	// can read them.
+	return directory.WriteFileAtomic(c.path, data, 0600)
+}
+
+// cacheKey identifies the response to a GET request, which also depends on
+// the media type asked for.
+func cacheKey(req *http.Request) string {
+	return req.URL.String() + " " + req.Header.Get("Accept")
+}
+
+// get returns the response saved for the request, if there is one.
+func (c *ResponseCache) get(req *http.Request) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[cacheKey(req)]
+	return cached, ok
+}
+
+// touch records that the response saved for the request is still current.
+func (c *ResponseCache) touch(req *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.entries[cacheKey(req)]; ok {
+		cached.Used = c.now()
+		c.entries[cacheKey(req)] = cached
+	}
+}
+
+// cacheable reports whether the response is worth saving, which is a JSON
+// response from the API, rather than the raw contents of a file, with an
+// ETag and a body that is not too large.
+func cacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+		return false
+	}
+	if resp.ContentLength > maxCachedBody {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// put saves the response to the request along with its body.
+func (c *ResponseCache) put(req *http.Request, resp *http.Response, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey(req)] = cachedResponse{
+		ETag:   resp.Header.Get("ETag"),
+		Header: resp.Header.Clone(),
+		Body:   body,
+		Used:   c.now(),
+	}
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+)
+
+// issueMarker is hidden within the body of the tracking issue so that the
+// issue can be found, and updated in place, on later runs.
+const issueMarker = "<!-- m-check:tracking-issue -->"
+
+// maxBodyLength is the longest body GitHub accepts for an issue or comment,
+// beyond which it is rejected.
+const maxBodyLength = 65536
+
+// truncatedNote ends a body that has been cut short to fit within the
+// length GitHub accepts.
+const truncatedNote = "\n\n_This report has been cut short, as it is longer than GitHub allows. Run m-check locally, or save the results as json, for the full report._\n"
+
+// truncateBody cuts the body short at the end of a line, along with a note
+// saying so, when it is longer than max. The length is measured in bytes,
+// which is never fewer than the characters GitHub counts.
+func truncateBody(body string, max int) string {
+	if len(body) <= max {
+		return body
+	}
+	cut := body[:max-len(truncatedNote)]
+	if i := strings.LastIndex(cut, "\n"); i >= 0 {
+		cut = cut[:i]
+	}
+	return cut + truncatedNote
+}
+
+// TrackingIssue describes the issue used to track the broken links of
+// the repository.
+type TrackingIssue struct {
+	// Title is the title given to the issue when it is created.
+	Title string
+	// Body is the markdown text listing the current failures.
+	Body string
+	// Labels are added to the issue when it is created.
+	Labels []string
+}
+
+// SyncTrackingIssue keeps a single open issue listing the broken links of the
+// repository. While there are broken links the issue is created, or updated
+// in place when it already exists, and once every link is working again the
+// issue is closed. The issue that has been changed is returned, which is nil
+// when there was nothing to change.
+func (r *Repository) SyncTrackingIssue(ctx context.Context, issue TrackingIssue, broken bool) (*github.Issue, error) {
+	existing, err := r.findTrackingIssue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body := github.String(truncateBody(issueMarker+"\n"+issue.Body, maxBodyLength))
+	switch {
+	case broken && existing != nil:
+		edited, _, err := r.client.Issues.Edit(ctx, r.Owner, r.RepoName, existing.GetNumber(), &github.IssueRequest{Body: body})
+		return edited, err
+	case broken:
+		req := &github.IssueRequest{Title: github.String(issue.Title), Body: body}
+		if len(issue.Labels) > 0 {
+			req.Labels = &issue.Labels
+		}
+		created, _, err := r.client.Issues.Create(ctx, r.Owner, r.RepoName, req)
+		return created, err
+	case existing != nil:
+		closed, _, err := r.client.Issues.Edit(ctx, r.Owner, r.RepoName, existing.GetNumber(), &github.IssueRequest{
+			Body:  body,
+			State: github.String("closed"),
+		})
+		return closed, err
+	}
+	return nil, nil
+}
+
+// findTrackingIssue looks through the open issues of the repository for one
+// holding the tracking issue marker, returning nil when there is none.
+func (r *Repository) findTrackingIssue(ctx context.Context) (*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{State: "open", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		issues, resp, err := r.client.Issues.ListByRepo(ctx, r.Owner, r.RepoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() && strings.Contains(issue.GetBody(), issueMarker) {
+				return issue, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSyncTrackingIssue(t *testing.T) {
+	tracking := `{"number": 5, "state": "open", "body": "` + issueMarker + `\nold"}`
+	tt := []struct {
+		name     string
+		existing string
+		broken   bool
+		method   string
+		path     string
+		state    string
+	}{
+		{"create", `[{"number": 4, "body": "unrelated"}]`, true, http.MethodPost, "/repos/jwhitt3r/m-check/issues", ""},
+		{"update", `[` + tracking + `]`, true, http.MethodPatch, "/repos/jwhitt3r/m-check/issues/5", ""},
+		{"close", `[` + tracking + `]`, false, http.MethodPatch, "/repos/jwhitt3r/m-check/issues/5", "closed"},
+		{"nothing", `[]`, false, "", "", ""},
+	}
+
+	t.Log("Given the need to track broken links with a single issue")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the tracking issue should %s", testID, test.name)
+		{
+			var method, path string
+			var req struct {
+				Title string
+				Body  string
+				State string
+			}
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/jwhitt3r/m-check/issues", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, test.existing)
+					return
+				}
+				method, path = r.Method, r.URL.Path
+				json.NewDecoder(r.Body).Decode(&req)
+				fmt.Fprint(w, `{"number": 6}`)
+			})
+			mux.HandleFunc("/repos/jwhitt3r/m-check/issues/5", func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				json.NewDecoder(r.Body).Decode(&req)
+				fmt.Fprint(w, tracking)
+			})
+			r := newFakeGithub(t, mux)
+
+			issue := TrackingIssue{Title: "Broken documentation links", Body: "1 broken."}
+			if _, err := r.SyncTrackingIssue(context.Background(), issue, test.broken); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to sync the issue : %v", failure, testID, err)
+			}
+			if method == test.method && path == test.path && req.State == test.state {
+				t.Logf("\t%s\tTest %d:\tShould call %q %q", success, testID, test.method, test.path)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould call %q %q : %q %q %q", failure, testID, test.method, test.path, method, path, req.State)
+			}
+		}
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	line := "| https://example.com/broken | README.md:1 | 404 |\n"
+	long := strings.Repeat(line, maxBodyLength/len(line)+10)
+	tt := []struct {
+		name      string
+		body      string
+		truncated bool
+	}{
+		{"short", "1 broken.", false},
+		{"long", long, true},
+	}
+
+	t.Log("Given the need to keep bodies within the length GitHub accepts")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen the body is %s", testID, test.name)
+		{
+			got := truncateBody(test.body, maxBodyLength)
+			if !test.truncated {
+				if got == test.body {
+					t.Logf("\t%s\tTest %d:\tShould leave the body as it is.", success, testID)
+				} else {
+					t.Errorf("\t%s\tTest %d:\tShould leave the body as it is : %q", failure, testID, got)
+				}
+				continue
+			}
+			if len(got) <= maxBodyLength && strings.HasSuffix(got, truncatedNote) && strings.HasSuffix(strings.TrimSuffix(got, truncatedNote), "| 404 |") {
+				t.Logf("\t%s\tTest %d:\tShould cut the body short at the end of a line, with a note.", success, testID)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould cut the body short at the end of a line, with a note : %d %q", failure, testID, len(got), got[len(got)-200:])
+			}
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// LinkValidator validates links to repositories, files, issues, pull
+// requests, releases and commits hosted on GitHub through the API, which
+// sees private repositories and is not rate limited as anonymous requests
+// to the website are.
+type LinkValidator struct {
+	// CheckLines checks that the lines a link is anchored to, e.g.,
+	// #L42-L50, still exist within the file.
+	CheckLines bool
+
+	client *github.Client
+	// host is the host of the website links are recognised for.
+	host string
+	// mu guards refs and branches.
+	mu sync.Mutex
+	// refs remembers the commit SHA each ref of a repository points at, or
+	// an empty string when the ref does not exist, keyed by owner/repo@ref.
+	refs map[string]string
+	// branches remembers whether each ref that exists is a branch, keyed
+	// by owner/repo@ref.
+	branches map[string]bool
+}
+
+// LinkValidator creates a LinkValidator sharing the connection of the
+// Repository, which must have been made by NewGithubConnection. Links to
+// github.com are recognised, or to the website of the GitHub Enterprise
+// Server instance the Repository connects to.
+func (r *Repository) LinkValidator() *LinkValidator {
+	host := "github.com"
+	if r.baseURL != nil {
+		host = r.baseURL.Host
+	}
+	return &LinkValidator{
+		client:   r.client,
+		host:     host,
+		refs:     make(map[string]string),
+		branches: make(map[string]bool),
+	}
+}
+
+// Validate checks the URL through the API when it links to something the
+// LinkValidator recognises, and reports ok as false otherwise.
+func (v *LinkValidator) Validate(ctx context.Context, rawurl string) (urlcheck.Verdict, bool) {
+	u, err := url.Parse(rawurl)
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(u.Host, "www."), v.host) {
+		return urlcheck.Verdict{}, false
+	}
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	if len(segments) < 2 {
+		return urlcheck.Verdict{}, false
+	}
+
+	if reserved[strings.ToLower(segments[0])] {
+		return urlcheck.Verdict{}, false
+	}
+
+	owner, name := segments[0], strings.TrimSuffix(segments[1], ".git")
+	rest := segments[2:]
+	if len(rest) == 0 {
+		// Pages of the website that are not repositories, but that are not
+		// reserved either, are left to be requested.
+		_, resp, err := v.client.Repositories.Get(ctx, owner, name)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return urlcheck.Verdict{}, false
+		}
+		return verdict(resp, err, ""), true
+	}
+
+	switch rest[0] {
+	case "blob", "tree":
+		if len(rest) < 2 {
+			return urlcheck.Verdict{}, false
+		}
+		return v.validateContents(ctx, owner, name, rest[1:], u.Fragment), true
+	case "issues":
+		number, ok := number(rest)
+		if !ok {
+			return urlcheck.Verdict{}, false
+		}
+		_, resp, err := v.client.Issues.Get(ctx, owner, name, number)
+		return verdict(resp, err, fmt.Sprintf("issue #%d does not exist", number)), true
+	case "pull":
+		number, ok := number(rest)
+		if !ok {
+			return urlcheck.Verdict{}, false
+		}
+		_, resp, err := v.client.PullRequests.Get(ctx, owner, name, number)
+		return verdict(resp, err, fmt.Sprintf("pull request #%d does not exist", number)), true
+	case "releases":
+		if len(rest) != 3 || rest[1] != "tag" {
+			return urlcheck.Verdict{}, false
+		}
+		_, resp, err := v.client.Repositories.GetReleaseByTag(ctx, owner, name, rest[2])
+		return verdict(resp, err, fmt.Sprintf("release %s does not exist", rest[2])), true
+	case "commit":
+		if len(rest) != 2 {
+			return urlcheck.Verdict{}, false
+		}
+		_, resp, err := v.client.Repositories.GetCommitSHA1(ctx, owner, name, rest[1], "")
+		return verdict(resp, err, fmt.Sprintf("commit %s does not exist", rest[1])), true
+	}
+	return urlcheck.Verdict{}, false
+}
+
+// reserved holds the top-level paths of the GitHub website that are pages of
+// the website rather than accounts, e.g., github.com/features/actions.
+var reserved = map[string]bool{
+	"about": true, "account": true, "apps": true, "blog": true, "collections": true,
+	"contact": true, "customer-stories": true, "enterprise": true, "events": true,
+	"explore": true, "features": true, "login": true, "logout": true,
+	"marketplace": true, "new": true, "notifications": true, "orgs": true,
+	"organizations": true, "pricing": true, "pulls": true, "issues": true,
+	"readme": true, "search": true, "security": true, "settings": true,
+	"site": true, "sponsors": true, "stars": true, "team": true, "topics": true,
+	"trending": true, "users": true,
+}
+
+// validateContents checks that the path exists at the ref, where the
+// segments hold the ref followed by the path. As a ref may itself hold
+// slashes, the shortest leading segments naming a ref that exists are
+// taken as the ref. When no ref exists, the branch is taken to have been
+// deleted. A link anchored to lines of a file on a branch is given the
+// permalink to the same lines at the commit the branch points at.
+func (v *LinkValidator) validateContents(ctx context.Context, owner string, name string, segments []string, fragment string) urlcheck.Verdict {
+	for i := 1; i <= len(segments); i++ {
+		ref := strings.Join(segments[:i], "/")
+		sha, err := v.resolveRef(ctx, owner, name, ref)
+		if err != nil {
+			return urlcheck.Verdict{Error: err.Error()}
+		}
+		if sha == "" {
+			continue
+		}
+
+		path := strings.Join(segments[i:], "/")
+		if path == "" {
+			return urlcheck.Verdict{StatusCode: http.StatusOK}
+		}
+		missing := fmt.Sprintf("%s does not exist at %s", path, ref)
+		// Only the lines linked to need the contents of the file, which
+		// are otherwise not downloaded.
+		if !v.CheckLines || !lineAnchor.MatchString(fragment) {
+			if resp, err := v.exists(ctx, owner, name, path, ref); err != nil {
+				return verdict(resp, err, missing)
+			}
+			return v.validateLines(ctx, owner, name, ref, sha, path, nil, fragment)
+		}
+		opts := &github.RepositoryContentGetOptions{Ref: ref}
+		file, _, resp, err := v.client.Repositories.GetContents(ctx, owner, name, path, opts)
+		if err != nil {
+			return verdict(resp, err, missing)
+		}
+		if file == nil {
+			// The path is a directory, which has no lines.
+			return urlcheck.Verdict{StatusCode: http.StatusOK}
+		}
+		return v.validateLines(ctx, owner, name, ref, sha, path, file, fragment)
+	}
+
+	// The repository itself may be missing, rather than the ref.
+	if _, resp, err := v.client.Repositories.Get(ctx, owner, name); err != nil {
+		return verdict(resp, err, "")
+	}
+	return urlcheck.Verdict{
+		StatusCode: http.StatusNotFound,
+		Reason:     fmt.Sprintf("ref %s does not exist, the branch may have been deleted", segments[0]),
+	}
+}
+
+// abbreviatedSHA matches a commit SHA, which is abbreviated to no fewer than
+// seven characters. A shorter ref, such as a branch named "dead", is never
+// taken to be a commit.
+var abbreviatedSHA = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// lineAnchor matches the fragment of a link to a line, or range of lines,
+// of a file, e.g., L42 or L42-L50, optionally with columns.
+var lineAnchor = regexp.MustCompile(`^L(\d+)(?:C\d+)?(?:-L(\d+)(?:C\d+)?)?$`)
+
+// validateLines checks a link to a path that exists, suggesting a permalink
+// when the link is anchored to lines of a file on a branch, and checking
+// that the lines still exist within the file when it is given.
+func (v *LinkValidator) validateLines(ctx context.Context, owner string, name string, ref string, sha string, path string, file *github.RepositoryContent, fragment string) urlcheck.Verdict {
+	ok := urlcheck.Verdict{StatusCode: http.StatusOK}
+	match := lineAnchor.FindStringSubmatch(fragment)
+	if match == nil {
+		return ok
+	}
+
+	if file != nil {
+		last, _ := strconv.Atoi(match[1])
+		if end, err := strconv.Atoi(match[2]); err == nil && end > last {
+			last = end
+		}
+		content, err := v.content(ctx, owner, name, file)
+		if err != nil {
+			return urlcheck.Verdict{Error: err.Error()}
+		}
+		lines := strings.Count(content, "\n")
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			lines++
+		}
+		if last > lines {
+			return urlcheck.Verdict{
+				StatusCode: http.StatusRequestedRangeNotSatisfiable,
+				Reason:     fmt.Sprintf("%s has %d lines at %s, fewer than the %s linked to", path, lines, ref, fragment),
+			}
+		}
+	}
+
+	branch, err := v.isBranch(ctx, owner, name, ref, sha)
+	if err != nil {
+		return urlcheck.Verdict{Error: err.Error()}
+	}
+	if branch {
+		ok.Suggestion = fmt.Sprintf("https://%s/%s/%s/blob/%s/%s#%s", v.host, owner, name, sha, path, fragment)
+	}
+	return ok
+}
+
+// exists checks that the path exists at the ref with a HEAD request, which
+// does not download the contents of the file.
+func (v *LinkValidator) exists(ctx context.Context, owner string, name string, path string, ref string) (*github.Response, error) {
+	escaped := (&url.URL{Path: strings.TrimSuffix(path, "/")}).String()
+	u := fmt.Sprintf("repos/%s/%s/contents/%s?ref=%s", owner, name, escaped, url.QueryEscape(ref))
+	req, err := v.client.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return v.client.Do(ctx, req, nil)
+}
+
+// content returns the contents of the file. The contents of files larger
+// than 1 MB are left out by the contents API, and are downloaded as a blob.
+func (v *LinkValidator) content(ctx context.Context, owner string, name string, file *github.RepositoryContent) (string, error) {
+	if file.GetEncoding() != "none" {
+		return file.GetContent()
+	}
+	blob, _, err := v.client.Git.GetBlobRaw(ctx, owner, name, file.GetSHA())
+	if err != nil {
+		return "", err
+	}
+	return string(blob), nil
+}
+
+// resolveRef returns the commit SHA the ref of the repository points at,
+// or an empty string when the ref does not exist, remembering the answer.
+func (v *LinkValidator) resolveRef(ctx context.Context, owner string, name string, ref string) (string, error) {
+	key := owner + "/" + name + "@" + ref
+	v.mu.Lock()
+	sha, ok := v.refs[key]
+	v.mu.Unlock()
+	if ok {
+		return sha, nil
+	}
+
+	sha, resp, err := v.client.Repositories.GetCommitSHA1(ctx, owner, name, ref, "")
+	if err != nil {
+		if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusUnprocessableEntity) {
+			return "", err
+		}
+		sha = ""
+	}
+
+	v.mu.Lock()
+	v.refs[key] = sha
+	v.mu.Unlock()
+	return sha, nil
+}
+
+// isBranch reports whether the ref, which points at the commit SHA, is a
+// branch rather than a commit or tag, remembering the answer.
+func (v *LinkValidator) isBranch(ctx context.Context, owner string, name string, ref string, sha string) (bool, error) {
+	if abbreviatedSHA.MatchString(ref) && strings.HasPrefix(sha, strings.ToLower(ref)) {
+		return false, nil
+	}
+
+	key := owner + "/" + name + "@" + ref
+	v.mu.Lock()
+	branch, ok := v.branches[key]
+	v.mu.Unlock()
+	if ok {
+		return branch, nil
+	}
+
+	_, resp, err := v.client.Repositories.GetBranch(ctx, owner, name, ref)
+	switch {
+	case err == nil:
+		branch = true
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		branch = false
+	default:
+		return false, err
+	}
+
+	v.mu.Lock()
+	v.branches[key] = branch
+	v.mu.Unlock()
+	return branch, nil
+}
+
+// verdict turns the response to an API request into a Verdict, explaining
+// a missing resource with the reason.
+func verdict(resp *github.Response, err error, reason string) urlcheck.Verdict {
+	if err == nil {
+		return urlcheck.Verdict{StatusCode: http.StatusOK}
+	}
+	var rateLimit *RateLimitError
+	if resp == nil || errors.As(err, &rateLimit) {
+		return urlcheck.Verdict{Error: err.Error()}
+	}
+	v := urlcheck.Verdict{StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		v.Reason = reason
+	}
+	return v
+}
+
+// number parses the number of an issue or pull request following its kind,
+// e.g., issues/12.
+func number(segments []string) (int, bool) {
+	if len(segments) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(segments[1])
+	return n, err == nil
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+func TestLinkValidator(t *testing.T) {
+	found := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/jwhitt3r/m-check", found(`{"name": "m-check"}`))
+	mux.HandleFunc("/repos/jwhitt3r/m-check/commits/main", found("1234567"))
+	mux.HandleFunc("/repos/jwhitt3r/m-check/commits/release/v2", found("89abcde"))
+	mux.HandleFunc("/repos/jwhitt3r/m-check/commits/1234567", found("1234567"))
+	var downloads int
+	mux.HandleFunc("/repos/jwhitt3r/m-check/contents/docs/guide.md", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			downloads++
+		}
+		if r.URL.Query().Get("ref") != "release/v2" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"type": "file", "path": "docs/guide.md"}`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/issues/12", found(`{"number": 12}`))
+	mux.HandleFunc("/repos/jwhitt3r/m-check/pulls/13", found(`{"number": 13}`))
+	mux.HandleFunc("/repos/jwhitt3r/m-check/releases/tags/v1.0.0", found(`{"tag_name": "v1.0.0"}`))
+	v := newFakeGithub(t, mux).LinkValidator()
+
+	tt := []struct {
+		url     string
+		ok      bool
+		verdict urlcheck.Verdict
+	}{
+		{"https://github.com/jwhitt3r/m-check", true, urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/blob/release/v2/docs/guide.md#usage", true, urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/blob/main/docs/guide.md", true, urlcheck.Verdict{StatusCode: 404, Reason: "docs/guide.md does not exist at main"}},
+		{"https://github.com/jwhitt3r/m-check/tree/feature-x/docs", true, urlcheck.Verdict{StatusCode: 404, Reason: "ref feature-x does not exist, the branch may have been deleted"}},
+		{"https://github.com/jwhitt3r/m-check/issues/12", true, urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/issues/99", true, urlcheck.Verdict{StatusCode: 404, Reason: "issue #99 does not exist"}},
+		{"https://github.com/jwhitt3r/m-check/pull/13/files", true, urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/releases/tag/v1.0.0", true, urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/commit/1234567", true, urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/compare/main...dev", false, urlcheck.Verdict{}},
+		{"https://gitlab.com/jwhitt3r/m-check", false, urlcheck.Verdict{}},
+		{"https://github.com/features/actions", false, urlcheck.Verdict{}},
+		{"https://github.com/sponsors/jwhitt3r", false, urlcheck.Verdict{}},
+		{"https://github.com/jwhitt3r/not-a-repository", false, urlcheck.Verdict{}},
+	}
+
+	t.Log("Given the need to validate links to GitHub through the API")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen validating %q", testID, test.url)
+		verdict, ok := v.Validate(context.Background(), test.url)
+		if ok == test.ok && verdict == test.verdict {
+			t.Logf("\t%s\tTest %d:\tShould find %v %+v", success, testID, test.ok, test.verdict)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould find %v %+v : %v %+v", failure, testID, test.ok, test.verdict, ok, verdict)
+		}
+	}
+	if downloads == 0 {
+		t.Logf("\t%s\tShould check that files exist without downloading them.", success)
+	} else {
+		t.Errorf("\t%s\tShould check that files exist without downloading them : %d downloaded", failure, downloads)
+	}
+}
+
+func TestLinkValidatorPermalinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/jwhitt3r/m-check/commits/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0a1b2c3d4e5f")
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "main"}`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/branches/v1.0.0", http.NotFound)
+	mux.HandleFunc("/repos/jwhitt3r/m-check/branches/0a1b", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "0a1b"}`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/contents/large.md", func(w http.ResponseWriter, r *http.Request) {
+		// Files larger than 1 MB are listed without their contents.
+		fmt.Fprint(w, `{"type": "file", "path": "large.md", "sha": "f00d", "encoding": "none", "content": ""}`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/git/blobs/f00d", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "# Large\n\nText\n")
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/contents/main.go", func(w http.ResponseWriter, r *http.Request) {
+		// The file holds three lines.
+		fmt.Fprint(w, `{"type": "file", "path": "main.go", "encoding": "base64", "content": "cGFja2FnZSBtYWluCgpmdW5jIG1haW4oKSB7fQo="}`)
+	})
+	v := newFakeGithub(t, mux).LinkValidator()
+	v.CheckLines = true
+
+	tt := []struct {
+		url     string
+		verdict urlcheck.Verdict
+	}{
+		{"https://github.com/jwhitt3r/m-check/blob/main/main.go", urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/blob/main/main.go#L2-L3", urlcheck.Verdict{StatusCode: 200, Suggestion: "https://github.com/jwhitt3r/m-check/blob/0a1b2c3d4e5f/main.go#L2-L3"}},
+		{"https://github.com/jwhitt3r/m-check/blob/v1.0.0/main.go#L3", urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/blob/0a1b2c3/main.go#L3", urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/blob/main/main.go#L2-L42", urlcheck.Verdict{StatusCode: 416, Reason: "main.go has 3 lines at main, fewer than the L2-L42 linked to"}},
+		{"https://github.com/jwhitt3r/m-check/blob/0a1b/main.go#L3", urlcheck.Verdict{StatusCode: 200, Suggestion: "https://github.com/jwhitt3r/m-check/blob/0a1b2c3d4e5f/main.go#L3"}},
+		{"https://github.com/jwhitt3r/m-check/blob/v1.0.0/large.md#L3", urlcheck.Verdict{StatusCode: 200}},
+		{"https://github.com/jwhitt3r/m-check/blob/v1.0.0/large.md#L4", urlcheck.Verdict{StatusCode: 416, Reason: "large.md has 3 lines at v1.0.0, fewer than the L4 linked to"}},
+	}
+
+	t.Log("Given the need to link to lines of code that will not move")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen validating %q", testID, test.url)
+		verdict, _ := v.Validate(context.Background(), test.url)
+		if verdict == test.verdict {
+			t.Logf("\t%s\tTest %d:\tShould find %+v", success, testID, test.verdict)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould find %+v : %+v", failure, testID, test.verdict, verdict)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitError is returned when the rate limit of the GitHub API has been
+// exhausted, and waiting for it to reset would take longer than allowed.
+type RateLimitError struct {
+	// Reset is when the rate limit resets.
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exhausted until %s", e.Reset.Format(time.RFC3339))
+}
+
+// maxAttempts is the number of times a request is sent before giving up on
+// a server that keeps rejecting it because of the rate limit.
+const maxAttempts = 3
+
+// rateLimitTransport keeps track of the rate limit reported by GitHub,
+// waiting for it to reset or failing with a RateLimitError once it has been
+// exhausted, and makes conditional requests with the ETag of the responses
+// saved within its cache, which GitHub does not count against the rate limit.
+type rateLimitTransport struct {
+	base http.RoundTripper
+	// maxWait is the longest to wait for the rate limit to reset, where
+	// zero fails straight away.
+	maxWait time.Duration
+	// sleep and now are replaced within tests.
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+	// cache holds the responses to request conditionally, where nil makes
+	// no conditional requests.
+	cache *ResponseCache
+
+	// mu guards the fields below.
+	mu        sync.Mutex
+	known     bool
+	remaining int
+	reset     time.Time
+}
+
+// newRateLimitTransport is a wrapper for the creation of a rateLimitTransport
+// type that sends requests through the base, saving responses to the cache
+// when it is not nil.
+func newRateLimitTransport(base http.RoundTripper, maxWait time.Duration, cache *ResponseCache) *rateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{
+		base:    base,
+		maxWait: maxWait,
+		sleep:   sleep,
+		now:     time.Now,
+		cache:   cache,
+	}
+}
+
+// sleep waits for the duration, returning early when the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RoundTrip sends the request, waiting beforehand when the rate limit is
+// known to be exhausted, and retrying once it resets when the server
+// rejects the request because of the rate limit.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if err := t.wait(req.Context(), t.exhaustedUntil()); err != nil {
+			return nil, err
+		}
+
+		sent := req
+		cached, ok := t.cached(req)
+		if ok {
+			sent = req.Clone(req.Context())
+			sent.Header.Set("If-None-Match", cached.ETag)
+		}
+
+		resp, err := t.base.RoundTrip(sent)
+		if err != nil {
+			return nil, err
+		}
+		t.update(resp.Header)
+
+		if until, limited := t.limitedUntil(resp); limited {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			// A request with a body cannot be sent again once it has
+			// been read, and a server that keeps rejecting requests once
+			// the rate limit should have reset is given up on.
+			if attempt == maxAttempts || (req.Body != nil && req.Body != http.NoBody) {
+				return nil, &RateLimitError{Reset: until}
+			}
+			if err := t.wait(req.Context(), until); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusNotModified && ok {
+			t.cache.touch(req)
+			return cached.response(req, resp), nil
+		}
+		return t.store(req, resp)
+	}
+}
+
+// cached returns the response saved for a GET request, if there is one.
+func (t *rateLimitTransport) cached(req *http.Request) (cachedResponse, bool) {
+	if t.cache == nil || req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return cachedResponse{}, false
+	}
+	return t.cache.get(req)
+}
+
+// store saves a JSON response to a GET request that has an ETag, so that it
+// can be requested conditionally later on. Bodies larger than maxCachedBody
+// are passed on without being saved.
+func (t *rateLimitTransport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	if t.cache == nil || req.Method != http.MethodGet || !cacheable(resp) {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.cache.put(req, resp, body)
+	return resp, nil
+}
+
+// response turns a 304 Not Modified response into the response that was
+// saved, keeping the rate limit headers of the new response.
+func (c cachedResponse) response(req *http.Request, notModified *http.Response) *http.Response {
+	notModified.Body.Close()
+	header := c.Header.Clone()
+	for _, name := range []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"} {
+		if value := notModified.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// update records the rate limit reported by the headers of a response.
+func (t *rateLimitTransport) update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.known = true
+	t.remaining = remaining
+	t.reset = time.Unix(reset, 0)
+	t.mu.Unlock()
+}
+
+// exhaustedUntil returns when the rate limit resets if it is known to have
+// been exhausted, or the zero time otherwise.
+func (t *rateLimitTransport) exhaustedUntil() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.known && t.remaining == 0 && t.reset.After(t.now()) {
+		return t.reset
+	}
+	return time.Time{}
+}
+
+// limitedUntil reports whether the server rejected the response because of
+// the primary or secondary rate limit, and when a retry may be made.
+func (t *rateLimitTransport) limitedUntil(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return t.now().Add(time.Duration(seconds) * time.Second), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.reset, true
+	}
+	return time.Time{}, false
+}
+
+// wait sleeps until the time when it is within the longest wait allowed,
+// returning a RateLimitError otherwise. The zero time does not wait.
+func (t *rateLimitTransport) wait(ctx context.Context, until time.Time) error {
+	if until.IsZero() {
+		return nil
+	}
+	d := until.Sub(t.now())
+	if d <= 0 {
+		return nil
+	}
+	if d > t.maxWait {
+		return &RateLimitError{Reset: until}
+	}
+	return t.sleep(ctx, d)
+}
+
+// quota returns the rate limit as last reported by GitHub.
+func (t *rateLimitTransport) quota() (remaining int, reset time.Time, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.remaining, t.reset, t.known
+}
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitConditionalRequests(t *testing.T) {
+	var sent, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		if r.URL.Path == "/raw" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, "# Title")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, `[{"type": "file"}]`)
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "responses.json")
+
+	tt := []struct {
+		name        string
+		url         string
+		body        string
+		notModified int
+	}{
+		{"requesting a JSON response for the first time", srv.URL + "/contents", `[{"type": "file"}]`, 0},
+		{"requesting the same JSON response within a later run", srv.URL + "/contents", `[{"type": "file"}]`, 1},
+		{"requesting raw contents", srv.URL + "/raw", "# Title", 1},
+		{"requesting the same raw contents within a later run", srv.URL + "/raw", "# Title", 1},
+	}
+
+	t.Log("Given the need to avoid spending the rate limit on unchanged responses")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen %s", testID, test.name)
+		// Each request is made as a new run would, with the cache read
+		// from and saved back to the same file.
+		c, err := OpenResponseCache(path)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to open the response cache : %v", failure, testID, err)
+		}
+		client := http.Client{Transport: newRateLimitTransport(nil, 0, c)}
+		resp, err := client.Get(test.url)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to make the request : %v", failure, testID, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && string(body) == test.body {
+			t.Logf("\t%s\tTest %d:\tShould receive the full response.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould receive the full response : %d %s", failure, testID, resp.StatusCode, body)
+		}
+		if notModified == test.notModified {
+			t.Logf("\t%s\tTest %d:\tShould only send the ETag of saved JSON responses.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould only send the ETag of saved JSON responses : %d not modified", failure, testID, notModified)
+		}
+		if err := c.Save(); err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to save the response cache : %v", failure, testID, err)
+		}
+	}
+	if sent == len(tt) {
+		t.Logf("\t%s\tShould send every request.", success)
+	} else {
+		t.Errorf("\t%s\tShould send every request : %d sent", failure, sent)
+	}
+}
+
+func TestResponseCacheExpires(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://api.github.com/repos/jwhitt3r/m-check", nil)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Etag": {`"abc"`}}}
+	path := filepath.Join(t.TempDir(), "responses.json")
+
+	tt := []struct {
+		unused time.Duration
+		kept   bool
+	}{
+		{time.Hour, true},
+		{maxCachedAge, false},
+	}
+
+	t.Log("Given the need to keep the response cache from growing without bound")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen a response has not been used for %v", testID, test.unused)
+		now := time.Unix(1600000000, 0)
+		c, err := OpenResponseCache(path)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to open the response cache : %v", failure, testID, err)
+		}
+		c.now = func() time.Time { return now }
+		c.put(req, resp, []byte("{}"))
+		now = now.Add(test.unused)
+		if err := c.Save(); err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to save the response cache : %v", failure, testID, err)
+		}
+
+		c, err = OpenResponseCache(path)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to open the response cache again : %v", failure, testID, err)
+		}
+		if _, ok := c.get(req); ok == test.kept {
+			t.Logf("\t%s\tTest %d:\tShould keep the response: %v.", success, testID, test.kept)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould keep the response: %v : %v", failure, testID, test.kept, ok)
+		}
+	}
+}
+
+func TestRateLimitExhausted(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	reset := now.Add(10 * time.Minute)
+
+	tt := []struct {
+		maxWait time.Duration
+		waited  time.Duration
+		failed  bool
+	}{
+		{0, 0, true},
+		{time.Minute, 0, true},
+		{time.Hour, 10 * time.Minute, false},
+	}
+
+	t.Log("Given the need to respect an exhausted rate limit")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen willing to wait %v for the rate limit to reset", testID, test.maxWait)
+		var sent int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sent++
+			if sent == 1 {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, "{}")
+		}))
+
+		var waited time.Duration
+		rl := newRateLimitTransport(nil, test.maxWait, nil)
+		rl.now = func() time.Time { return now.Add(waited) }
+		rl.sleep = func(ctx context.Context, d time.Duration) error {
+			waited += d
+			return nil
+		}
+		client := http.Client{Transport: rl}
+
+		resp, err := client.Get(srv.URL)
+		srv.Close()
+		var rateLimit *RateLimitError
+		switch {
+		case test.failed && errors.As(err, &rateLimit) && rateLimit.Reset.Equal(reset):
+			t.Logf("\t%s\tTest %d:\tShould fail with a RateLimitError : %v", success, testID, err)
+		case !test.failed && err == nil && resp.StatusCode == http.StatusOK && waited == test.waited:
+			resp.Body.Close()
+			t.Logf("\t%s\tTest %d:\tShould wait %v and retry the request.", success, testID, test.waited)
+		default:
+			t.Errorf("\t%s\tTest %d:\tShould fail %v after waiting %v : %v, waited %v", failure, testID, test.failed, test.waited, err, waited)
+		}
+
+		remaining, _, ok := rl.quota()
+		if ok && remaining == 0 {
+			t.Logf("\t%s\tTest %d:\tShould record the remaining quota.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould record the remaining quota : %d %v", failure, testID, remaining, ok)
+		}
+	}
+}
@@ -4,8 +4,8 @@
 package repo
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,10 +14,12 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/jwhitt3r/m-check/internal/changes"
+	"github.com/jwhitt3r/m-check/internal/markdown"
 	"github.com/jwhitt3r/m-check/internal/platform/directory"
 
 	"github.com/google/go-github/v33/github"
@@ -36,17 +38,42 @@ type Repository struct {
 	// Links holds all the URLS that have been gathered from the the documents that
 	// have been downloaded from the Documentation folder of a repository
 	client *github.Client
+	// httpClient downloads the raw contents of files, authenticating with
+	// the same token as the client.
+	httpClient *http.Client
+	// baseURL and uploadURL point the client at a GitHub Enterprise Server
+	// instance, and are nil when connecting to github.com.
+	baseURL   *url.URL
+	uploadURL *url.URL
+	// maxWait is the longest to wait for the rate limit of the GitHub API to
+	// reset once it has been exhausted, where zero fails straight away.
+	maxWait time.Duration
+	// rateLimit tracks the rate limit of the connection, and is shared by
+	// every request made through it.
+	rateLimit *rateLimitTransport
+	// responses holds the responses of the GitHub API to request
+	// conditionally, and is nil when no conditional requests are made.
+	responses *ResponseCache
+	// app authenticates as an installation of a GitHub App in place of the
+	// token when set.
+	app *App
+	// tokenSource provides the token of the connection, and is nil when
+	// connecting anonymously.
+	tokenSource oauth2.TokenSource
 }
 
 // GithubContents recursively looks through any directory within the Documentation folder
 // of a repository and appends the FilesURL of a Markdown file to a slice of strings to be
-// downloaded later.
-func (r *Repository) GithubContents(ctx context.Context, path string, filesDownloadURL *[]string) {
-
+// downloaded later. A subdirectory that cannot be listed does not stop the others from
+// being looked through, and is reported through a *PartialError, along with which the
+// slice holds every file that could be found.
+func (r *Repository) GithubContents(ctx context.Context, path string, filesDownloadURL *[]string) error {
 	_, dirContents, _, err := r.client.Repositories.GetContents(ctx, r.Owner, r.RepoName, path, nil)
 	if err != nil {
-		fmt.Printf("%v\n", err)
+		return err
 	}
+
+	var errs []error
 	for _, element := range dirContents {
 		switch element.GetType() {
 		case "file":
@@ -54,10 +81,77 @@ func (r *Repository) GithubContents(ctx context.Context, path string, filesDownl
 				*filesDownloadURL = append(*filesDownloadURL, element.GetDownloadURL())
 			}
 		case "dir":
-			r.GithubContents(ctx, element.GetPath(), filesDownloadURL)
+			err := r.GithubContents(ctx, element.GetPath(), filesDownloadURL)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var partial *PartialError
+			switch {
+			case errors.As(err, &partial):
+				errs = append(errs, partial.Errors...)
+			case err != nil:
+				errs = append(errs, fmt.Errorf("listing %s: %w", element.GetPath(), err))
+			}
 		}
 	}
+	if len(errs) > 0 {
+		return &PartialError{Errors: errs}
+	}
+	return nil
+}
+
+// PartialError is returned by GithubContents when some of the directories of
+// the repository could not be listed.
+type PartialError struct {
+	// Errors holds each failure.
+	Errors []error
+}
+
+func (e *PartialError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "incomplete, " + strings.Join(msgs, "; ")
+}
+
+// FullName returns the owner and name of the repository, e.g., jwhitt3r/m-check.
+func (r *Repository) FullName() string {
+	return r.Owner + "/" + r.RepoName
+}
+
+// PullRequestFiles lists the files that have been added or modified by a
+// pull request, along with the lines that have changed within them. The
+// paths of the returned Set are relative to the root of the repository.
+func (r *Repository) PullRequestFiles(ctx context.Context, number int) (changes.Set, error) {
+	set := make(changes.Set)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		files, resp, err := r.client.PullRequests.ListFiles(ctx, r.Owner, r.RepoName, number, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if file.GetStatus() == "removed" {
+				continue
+			}
+			if file.Patch == nil {
+				set[file.GetFilename()] = nil
+				continue
+			}
+			lines, err := changes.ParsePatch(file.GetPatch())
+			if err != nil {
+				return nil, fmt.Errorf("reading patch of %s: %w", file.GetFilename(), err)
+			}
+			set[file.GetFilename()] = lines
+		}
 
+		if resp.NextPage == 0 {
+			return set, nil
+		}
+		opts.Page = resp.NextPage
+	}
 }
 
 // NewRepository wraps the creation of a Repository type
@@ -70,52 +164,171 @@ func NewRepository(owner string, reponame string, token string) *Repository {
 	return &r
 }
 
+// UseEnterprise points the Repository at a GitHub Enterprise Server
+// instance rather than github.com. The upload URL defaults to the base
+// URL when empty, and "api/v3/" is appended to the base URL when missing.
+// This must be called before NewGithubConnection.
+func (r *Repository) UseEnterprise(baseURL string, uploadURL string) error {
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+	c, err := github.NewEnterpriseClient(baseURL, uploadURL, nil)
+	if err != nil {
+		return fmt.Errorf("parsing GitHub Enterprise Server URL: %w", err)
+	}
+	r.baseURL = c.BaseURL
+	r.uploadURL = c.UploadURL
+	return nil
+}
+
+// WaitForRateLimit allows requests to wait up to the duration for the rate
+// limit of the GitHub API to reset once it has been exhausted, rather than
+// failing with a RateLimitError. This must be called before NewGithubConnection.
+func (r *Repository) WaitForRateLimit(max time.Duration) {
+	r.maxWait = max
+}
+
+// UseResponseCache saves the responses of the GitHub API to the cache, and
+// requests them conditionally whenever they are requested again, which does
+// not count against the rate limit when they have not changed. This must be
+// called before NewGithubConnection.
+func (r *Repository) UseResponseCache(c *ResponseCache) {
+	r.responses = c
+}
+
+// RateLimit returns the number of requests that can still be made before the
+// rate limit of the GitHub API is exhausted, and when it resets, as last
+// reported by GitHub. The ok result is false until GitHub has reported it.
+func (r *Repository) RateLimit() (remaining int, reset time.Time, ok bool) {
+	if r.rateLimit == nil {
+		return 0, time.Time{}, false
+	}
+	return r.rateLimit.quota()
+}
+
 // NewGithubConnection creates a connection to GitHub with or without a
 // personal access token. However, with a personal token this increases
-// the number of times you can connect to a repository.
+// the number of times you can connect to a repository. A GitHub App set
+// by UseApp is used in place of the token. Requests made
+// through the connection respect the rate limit of the GitHub API, and
+// are made conditionally when they are held by the cache set by
+// UseResponseCache.
 func (r *Repository) NewGithubConnection() {
-	ctx := context.Background()
-	if r.token != "" {
-		ts := oauth2.StaticTokenSource(
+	r.rateLimit = newRateLimitTransport(http.DefaultTransport, r.maxWait, r.responses)
+	r.httpClient = &http.Client{Transport: r.rateLimit}
+	switch {
+	case r.app != nil:
+		// The installation token is reused until it is about to expire,
+		// when a new one is created.
+		r.tokenSource = oauth2.ReuseTokenSource(nil, newAppTokenSource(*r.app, r, r.rateLimit))
+	case r.token != "":
+		r.tokenSource = oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: r.token},
 		)
-		tc := oauth2.NewClient(ctx, ts)
+	}
+	if r.tokenSource != nil {
+		r.httpClient = &http.Client{Transport: &oauth2.Transport{Source: r.tokenSource, Base: r.rateLimit}}
+	}
+
+	r.client = github.NewClient(r.httpClient)
+	if r.baseURL != nil {
+		r.client.BaseURL = r.baseURL
+		r.client.UploadURL = r.uploadURL
+	}
+}
 
-		r.client = github.NewClient(tc)
-		return
+// Client returns the connection to GitHub made by NewGithubConnection.
+func (r *Repository) Client() *github.Client {
+	return r.client
+}
+
+// HTTPClient returns the client used to download the raw contents of files,
+// which authenticates with the same token as the connection to GitHub.
+func (r *Repository) HTTPClient() *http.Client {
+	return r.httpClient
+}
+
+// Token returns the token the connection authenticates with, which is an
+// installation token when authenticating as a GitHub App, or an empty
+// string when connecting anonymously.
+func (r *Repository) Token() (string, error) {
+	if r.tokenSource == nil {
+		return "", nil
+	}
+	token, err := r.tokenSource.Token()
+	if err != nil {
+		return "", err
 	}
-	r.client = github.NewClient(nil)
+	return token.AccessToken, nil
+}
 
+// WebURL returns the address of the website of the GitHub server the
+// Repository is hosted on, e.g., https://github.com.
+func (r *Repository) WebURL() string {
+	if r.baseURL == nil {
+		return "https://github.com"
+	}
+	return r.baseURL.Scheme + "://" + r.baseURL.Host
+}
+
+// sibling creates a Repository of the same GitHub server, sharing the
+// connection that has already been made.
+func (r *Repository) sibling(owner string, reponame string) *Repository {
+	s := *r
+	s.Owner = owner
+	s.RepoName = reponame
+	return &s
+}
+
+// download fetches the raw contents of a file, authenticating with the
+// token of the Repository so that the files of private repositories, and
+// of GitHub Enterprise Server instances, can be downloaded.
+func (r *Repository) download(ctx context.Context, fileURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", fileURL, resp.Status)
+	}
+	return resp, nil
 }
 
 // FetchAndCreate will download all the files that have been
 // collected by the GithubContents function, and save them into
-// the local repository.
-func (r *Repository) FetchAndCreate(basepath string, fileURLS []string) error {
+// the local repository. A file that cannot be downloaded is logged
+// and skipped, while a file that cannot be saved, or the context being
+// cancelled, stops the download.
+func (r *Repository) FetchAndCreate(ctx context.Context, basepath string, fileURLS []string) error {
 
 	for _, fileURL := range fileURLS {
-		resp, err := http.Get(fileURL)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resp, err := r.download(ctx, fileURL)
 		if err != nil {
 			log.Printf("Failed to fetch URL: %v\n", err)
+			continue
 		}
 
 		u, err := url.Parse(fileURL)
 		if err != nil {
+			resp.Body.Close()
 			log.Printf("Failed to parse URL: %v\n", err)
+			continue
 		}
 		path := strings.ReplaceAll(u.Path, "/", ".")
 		pathFirstIndex := strings.Index(path, ".docs")
 
-		f, err := os.Create(directory.FilePathTemplate(basepath, r.Owner, r.RepoName) + path[pathFirstIndex+6:])
-		if err != nil {
-			log.Fatalf("Failed to create file: %v\n", err)
-		}
-		defer f.Close()
-
-		_, err = io.Copy(f, resp.Body)
+		err = save(directory.FilePathTemplate(basepath, r.Owner, r.RepoName)+path[pathFirstIndex+6:], resp.Body)
 		resp.Body.Close()
 		if err != nil {
-			log.Fatalf("Failed to copy contents to file: %v\n", err)
+			return err
 		}
 	}
 
@@ -123,87 +336,98 @@ func (r *Repository) FetchAndCreate(basepath string, fileURLS []string) error {
 
 }
 
+// save writes the contents of the reader to a new file at path.
+func save(path string, contents io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	if _, err := io.Copy(f, contents); err != nil {
+		f.Close()
+		return fmt.Errorf("copying contents to file: %w", err)
+	}
+	return f.Close()
+}
+
 // FileNames gathers all the downloaded files found within the docs
 // directory and stores them into the Files Slice.
-func (r *Repository) FileNames(basepath string) []string {
+func (r *Repository) FileNames(basepath string) ([]string, error) {
 	var f []string
 	files, err := ioutil.ReadDir(directory.FilePathTemplate(basepath, r.Owner, r.RepoName))
 
 	if err != nil {
-		log.Fatalf("Could not read files from directory: %v\n", err)
+		return nil, fmt.Errorf("could not read files from directory: %w", err)
 	}
 
 	for _, fileName := range files {
 		f = append(f, fileName.Name())
 	}
 
-	return f
+	return f, nil
 }
 
 // Parse traverses a markdown file that has been downloaded within the
 // documentation folder within the repository, and compares a regular
 // expression to find any possible links within the documentation.
 func (r *Repository) Parse(f io.Reader) []string {
-
-	var links []string
-	// The Regex will aim to locate any address that has the following structure:
-	// https://github.com/jwhitt3r. An example of this would be within a markdown
-	// file as: [Jwhitt3rs GitHub](https://github.com/jwhitt3r) or
-	// file as: [Jwhitt3rs GitHub]("https://github.com/jwhitt3r")
-	markdownURL := regexp.MustCompile(`https?://[^()]+?[^)"]+`)
-
-	scanner := bufio.NewScanner(f)
-
-	for scanner.Scan() {
-
-		submatchall := markdownURL.FindAllString(scanner.Text(), -1)
-
-		for _, element := range submatchall {
-			links = append(links, strings.TrimSpace(element))
-		}
-
-	}
-
-	return links
+	return markdown.Parse(f)
 }
 
 // ParseFileHandler will generate a file handler, which is then passed to the parse
 // method to be analysed. This allows for the separation of duties between the parser
 // and the handling of files. This function will return the links that have been gathered
 // from the parsed file.
-func (r *Repository) ParseFileHandler(basepath string, fileName string) []string {
+func (r *Repository) ParseFileHandler(basepath string, fileName string) ([]string, error) {
 	var links []string
 	if filepath.Ext(fileName) == ".md" {
 		f, err := os.Open(directory.FilePathTemplate(basepath, r.Owner, r.RepoName) + fileName)
 		if err != nil {
-			log.Fatalf("Failed to open file: %v\n", err)
+			return nil, fmt.Errorf("failed to open file: %w", err)
 		}
 		defer f.Close()
 		links = r.Parse(f)
 
 	}
-	return links
+	return links, nil
 }
 
 // ParseBatch wraps a concurrent method for parsing a file
 // which the outcome is then appended to a slice of strings,
-// to be passed to the URLCheckBatch function.
-func (r *Repository) ParseBatch(basepath string, files []string) []string {
-	ch := make(chan []string, len(files))
+// to be passed to the URLCheckBatch function. Files that cannot
+// be opened are reported through the returned slice of errors
+// rather than stopping the batch, as are the files skipped once
+// the context has been cancelled.
+func (r *Repository) ParseBatch(ctx context.Context, basepath string, files []string) ([]string, []error) {
+	type result struct {
+		links []string
+		err   error
+	}
+
+	ch := make(chan result, len(files))
 	var links []string
+	var errs []error
 	var wg sync.WaitGroup
 	wg.Add(len(files))
 	for _, fileName := range files {
 		go func(fileName string) {
-			ch <- r.ParseFileHandler(basepath, fileName)
-			wg.Done()
+			defer wg.Done()
+			if err := ctx.Err(); err != nil {
+				ch <- result{err: err}
+				return
+			}
+			links, err := r.ParseFileHandler(basepath, fileName)
+			ch <- result{links: links, err: err}
 		}(fileName)
 
 	}
 	wg.Wait()
 	close(ch)
 	for value := range ch {
-		links = append(links, value...)
+		if value.err != nil {
+			errs = append(errs, value.err)
+			continue
+		}
+		links = append(links, value.links...)
 	}
-	return links
+	return links, errs
 }
@@ -1,7 +1,13 @@
 package repo
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -29,3 +35,40 @@ func TestParse(t *testing.T) {
 
 	}
 }
+
+func TestGithubContents(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/jwhitt3r/m-check/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"type": "file", "name": "README.md", "path": "docs/README.md", "download_url": "https://raw.example.com/docs/README.md"},
+			{"type": "dir", "name": "broken", "path": "docs/broken"},
+			{"type": "dir", "name": "guides", "path": "docs/guides"}
+		]`)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/contents/docs/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/repos/jwhitt3r/m-check/contents/docs/guides", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"type": "file", "name": "setup.md", "path": "docs/guides/setup.md", "download_url": "https://raw.example.com/docs/guides/setup.md"},
+			{"type": "file", "name": "logo.png", "path": "docs/guides/logo.png", "download_url": "https://raw.example.com/docs/guides/logo.png"}
+		]`)
+	})
+	r := newFakeGithub(t, mux)
+
+	t.Log("Given a repository with a directory that cannot be listed")
+	var files []string
+	err := r.GithubContents(context.Background(), "docs", &files)
+	var partial *PartialError
+	if errors.As(err, &partial) && len(partial.Errors) == 1 && strings.Contains(partial.Errors[0].Error(), "docs/broken") {
+		t.Logf("\t%s\tShould report the directory that could not be listed : %v", success, err)
+	} else {
+		t.Errorf("\t%s\tShould report the directory that could not be listed : %v", failure, err)
+	}
+	want := []string{"https://raw.example.com/docs/README.md", "https://raw.example.com/docs/guides/setup.md"}
+	if reflect.DeepEqual(files, want) {
+		t.Logf("\t%s\tShould keep looking through the other directories.", success)
+	} else {
+		t.Errorf("\t%s\tShould keep looking through the other directories : %v", failure, files)
+	}
+}
@@ -0,0 +1,58 @@
+package report
+
+import "github.com/jwhitt3r/m-check/internal/urlcheck"
+
+// key identifies a link within a baseline by the URL and the file it was
+// found in, ignoring its line, which moves as the file is edited.
+func key(result urlcheck.Result) string {
+	return fileKey(result) + "\x00" + result.URL
+}
+
+// fileKey identifies the file a link was found in.
+func fileKey(result urlcheck.Result) string {
+	return result.Repository + "\x00" + result.File
+}
+
+// Failures returns every broken link of the Report, including those already
+// known from a baseline, which is what is recorded as the next baseline.
+func (rep *Report) Failures() []urlcheck.Result {
+	return append(append([]urlcheck.Result{}, rep.Known...), rep.Broken()...)
+}
+
+// ApplyBaseline moves the broken links recorded within the baseline out of
+// the Results and into Known, so that only newly broken links are reported
+// as broken. Links of the baseline that are no longer broken, within the
+// files that have been checked, are added to Fixed.
+func (rep *Report) ApplyBaseline(baseline []urlcheck.Result) {
+	known := make(map[string]bool)
+	for _, result := range baseline {
+		known[key(result)] = true
+	}
+
+	checked := make(map[string]bool)
+	var results []urlcheck.Result
+	for _, result := range rep.Results {
+		checked[fileKey(result)] = true
+		if result.Broken() && known[key(result)] {
+			rep.Known = append(rep.Known, result)
+			continue
+		}
+		results = append(results, result)
+	}
+	rep.Results = results
+
+	listed := make(map[string]bool)
+	for _, result := range rep.Known {
+		listed[key(result)] = true
+	}
+	for _, result := range rep.Fixed {
+		listed[key(result)] = true
+	}
+	for _, result := range baseline {
+		if listed[key(result)] || !checked[fileKey(result)] {
+			continue
+		}
+		listed[key(result)] = true
+		rep.Fixed = append(rep.Fixed, result)
+	}
+}
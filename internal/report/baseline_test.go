@@ -0,0 +1,60 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	baseline := []urlcheck.Result{
+		{Link: markdown.Link{URL: "https://example.com/legacy", File: "README.md", Line: 3}, StatusCode: 404},
+		{Link: markdown.Link{URL: "https://example.com/repaired", File: "README.md", Line: 8}, StatusCode: 404},
+		{Link: markdown.Link{URL: "https://example.com/unchecked", File: "docs/other.md", Line: 1}, StatusCode: 404},
+	}
+	rep := New([]urlcheck.Result{
+		{Link: markdown.Link{URL: "https://example.com/legacy", File: "README.md", Line: 5}, StatusCode: 404},
+		{Link: markdown.Link{URL: "https://example.com/repaired", File: "README.md", Line: 9}, StatusCode: 200},
+		{Link: markdown.Link{URL: "https://example.com/new", File: "README.md", Line: 12}, StatusCode: 404},
+	})
+
+	t.Log("Given a baseline of known broken links")
+	{
+		rep.ApplyBaseline(baseline)
+		if broken := rep.Broken(); len(broken) == 1 && broken[0].URL == "https://example.com/new" {
+			t.Logf("\t%s\tShould only report the newly broken link, even though the known link has moved.", success)
+		} else {
+			t.Errorf("\t%s\tShould only report the newly broken link : %v", failure, broken)
+		}
+		if len(rep.Known) == 1 && rep.Known[0].URL == "https://example.com/legacy" {
+			t.Logf("\t%s\tShould keep the known broken link aside.", success)
+		} else {
+			t.Errorf("\t%s\tShould keep the known broken link aside : %v", failure, rep.Known)
+		}
+		if len(rep.Fixed) == 1 && rep.Fixed[0].URL == "https://example.com/repaired" {
+			t.Logf("\t%s\tShould list the fixed link, but not the link of a file that was not checked.", success)
+		} else {
+			t.Errorf("\t%s\tShould list the fixed link, but not the link of a file that was not checked : %v", failure, rep.Fixed)
+		}
+
+		rep.ApplyBaseline(rep.Failures())
+		if len(rep.Broken()) == 0 && len(rep.Known) == 2 && len(rep.Fixed) == 1 {
+			t.Logf("\t%s\tShould accept every broken link when the baseline is updated.", success)
+		} else {
+			t.Errorf("\t%s\tShould accept every broken link when the baseline is updated : %d broken, %d known, %d fixed", failure, len(rep.Broken()), len(rep.Known), len(rep.Fixed))
+		}
+
+		var buf bytes.Buffer
+		if err := rep.Write(&buf, "markdown"); err != nil {
+			t.Fatalf("\t%s\tShould be able to render the results as markdown : %v", failure, err)
+		}
+		if strings.Contains(buf.String(), "3 links checked, 0 broken, 2 more already known from the baseline.") && strings.Contains(buf.String(), "| README.md:8 | https://example.com/repaired |") {
+			t.Logf("\t%s\tShould render the known and fixed links.", success)
+		} else {
+			t.Errorf("\t%s\tShould render the known and fixed links : %s", failure, buf.String())
+		}
+	}
+}
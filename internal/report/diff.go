@@ -0,0 +1,146 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// Change is a link whose outcome differs between two Reports.
+type Change struct {
+	Old urlcheck.Result `json:"old"`
+	New urlcheck.Result `json:"new"`
+}
+
+// Diff holds what changed between two Reports. Links are matched by their
+// URL and the file they were found in, ignoring their line.
+type Diff struct {
+	// Broken holds the links that are broken now but were not before,
+	// including links that have been added since.
+	Broken []urlcheck.Result `json:"broken,omitempty"`
+	// Fixed holds the links that were broken before but are not now.
+	Fixed []urlcheck.Result `json:"fixed,omitempty"`
+	// Changed holds the links whose status changed, other than by breaking
+	// or being fixed, e.g., a 404 that has become a 410.
+	Changed []Change `json:"changed,omitempty"`
+	// Redirected holds the links that have started to be redirected.
+	Redirected []Change `json:"redirected,omitempty"`
+}
+
+// Compare finds what changed between the old and current Reports. The broken
+// links known from a baseline are compared as any other.
+func Compare(old *Report, current *Report) *Diff {
+	before := make(map[string]urlcheck.Result)
+	for _, result := range append(append([]urlcheck.Result{}, old.Results...), old.Known...) {
+		if _, ok := before[key(result)]; !ok {
+			before[key(result)] = result
+		}
+	}
+
+	var d Diff
+	seen := make(map[string]bool)
+	after := append(append([]urlcheck.Result{}, current.Results...), current.Known...)
+	urlcheck.Sort(after)
+	for _, result := range after {
+		k := key(result)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		previous, ok := before[k]
+		switch {
+		case !ok:
+			if result.Broken() {
+				d.Broken = append(d.Broken, result)
+			}
+		case result.Broken() && !previous.Broken():
+			d.Broken = append(d.Broken, result)
+		case !result.Broken() && previous.Broken():
+			d.Fixed = append(d.Fixed, result)
+		case Status(result) != Status(previous):
+			d.Changed = append(d.Changed, Change{Old: previous, New: result})
+		case result.Redirect != "" && previous.Redirect == "":
+			d.Redirected = append(d.Redirected, Change{Old: previous, New: result})
+		}
+	}
+	return &d
+}
+
+// Write renders the Diff in the named format.
+func (d *Diff) Write(w io.Writer, format string) error {
+	switch format {
+	case "text", "":
+		return d.writeText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	case "markdown":
+		return d.writeMarkdown(w)
+	}
+	return fmt.Errorf("unknown report format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// writeText renders each change on its own line, prefixed by its kind.
+func (d *Diff) writeText(w io.Writer) error {
+	for _, result := range d.Broken {
+		if _, err := fmt.Fprintf(w, "Broken: %s - %s - %s\n", result.URL, Location(result), Status(result)); err != nil {
+			return err
+		}
+	}
+	for _, result := range d.Fixed {
+		if _, err := fmt.Fprintf(w, "Fixed: %s - %s\n", result.URL, Location(result)); err != nil {
+			return err
+		}
+	}
+	for _, change := range d.Changed {
+		if _, err := fmt.Fprintf(w, "Changed: %s - %s - %s -> %s\n", change.New.URL, Location(change.New), Status(change.Old), Status(change.New)); err != nil {
+			return err
+		}
+	}
+	for _, change := range d.Redirected {
+		if _, err := fmt.Fprintf(w, "Redirected: %s - %s -> %s\n", change.New.URL, Location(change.New), change.New.Redirect); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown renders a table for each kind of change that was found.
+func (d *Diff) writeMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "## m-check changes\n\n%d newly broken, %d fixed, %d changed status, %d started redirecting.\n",
+		len(d.Broken), len(d.Fixed), len(d.Changed), len(d.Redirected))
+
+	if len(d.Broken) > 0 {
+		fmt.Fprintf(w, "\n### Newly broken\n\n| Location | Link | Status |\n| --- | --- | --- |\n")
+		for _, result := range d.Broken {
+			fmt.Fprintf(w, "| %s | %s | %s |\n", Location(result), escape(result.URL), escape(Status(result)))
+		}
+	}
+	if len(d.Fixed) > 0 {
+		fmt.Fprintf(w, "\n### Fixed\n\n| Location | Link |\n| --- | --- |\n")
+		for _, result := range d.Fixed {
+			fmt.Fprintf(w, "| %s | %s |\n", Location(result), escape(result.URL))
+		}
+	}
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(w, "\n### Changed status\n\n| Location | Link | Before | Now |\n| --- | --- | --- | --- |\n")
+		for _, change := range d.Changed {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n", Location(change.New), escape(change.New.URL), escape(Status(change.Old)), escape(Status(change.New)))
+		}
+	}
+	if len(d.Redirected) > 0 {
+		fmt.Fprintf(w, "\n### Started redirecting\n\n| Location | Link | Redirected To |\n| --- | --- | --- |\n")
+		for _, change := range d.Redirected {
+			_, err := fmt.Fprintf(w, "| %s | %s | %s |\n", Location(change.New), escape(change.New.URL), escape(change.New.Redirect))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+func TestCompare(t *testing.T) {
+	old := New([]urlcheck.Result{
+		{Link: markdown.Link{URL: "https://example.com/breaks", File: "README.md", Line: 1}, StatusCode: 200},
+		{Link: markdown.Link{URL: "https://example.com/fixed", File: "README.md", Line: 2}, StatusCode: 404},
+		{Link: markdown.Link{URL: "https://example.com/gone", File: "README.md", Line: 3}, StatusCode: 404},
+		{Link: markdown.Link{URL: "https://example.com/moved", File: "README.md", Line: 4}, StatusCode: 200},
+		{Link: markdown.Link{URL: "https://example.com/same", File: "README.md", Line: 5}, StatusCode: 200},
+	})
+	current := New([]urlcheck.Result{
+		{Link: markdown.Link{URL: "https://example.com/breaks", File: "README.md", Line: 1}, Error: "no such host"},
+		{Link: markdown.Link{URL: "https://example.com/fixed", File: "README.md", Line: 2}, StatusCode: 200},
+		{Link: markdown.Link{URL: "https://example.com/gone", File: "README.md", Line: 3}, StatusCode: 410},
+		{Link: markdown.Link{URL: "https://example.com/moved", File: "README.md", Line: 4}, StatusCode: 200, Redirect: "https://example.org/moved"},
+		{Link: markdown.Link{URL: "https://example.com/same", File: "README.md", Line: 9}, StatusCode: 200},
+		{Link: markdown.Link{URL: "https://example.com/added", File: "docs/a.md", Line: 1}, StatusCode: 404},
+	})
+
+	t.Log("Given two saved result sets")
+	{
+		d := Compare(old, current)
+		tt := []struct {
+			kind string
+			got  int
+			want int
+		}{
+			{"newly broken", len(d.Broken), 2},
+			{"fixed", len(d.Fixed), 1},
+			{"changed status", len(d.Changed), 1},
+			{"started redirecting", len(d.Redirected), 1},
+		}
+		for testID, test := range tt {
+			if test.got == test.want {
+				t.Logf("\t%s\tTest %d:\tShould find %d %s links.", success, testID, test.want, test.kind)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould find %d %s links : %d", failure, testID, test.want, test.kind, test.got)
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := d.Write(&buf, "markdown"); err != nil {
+			t.Fatalf("\t%s\tShould be able to render the changes as markdown : %v", failure, err)
+		}
+		if strings.Contains(buf.String(), "| README.md:3 | https://example.com/gone | 404 | 410 |") && strings.Contains(buf.String(), "| README.md:4 | https://example.com/moved | https://example.org/moved |") {
+			t.Logf("\t%s\tShould render the changes.", success)
+		} else {
+			t.Errorf("\t%s\tShould render the changes : %s", failure, buf.String())
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Package report saves and renders the results of checking the links
+// found within markdown documentation.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// Formats lists the names of every format a Report can be written in.
+var Formats = []string{"text", "json", "markdown"}
+
+// Report holds the results of a check, and is what is saved as a results
+// file so that it can be rendered into another format later on.
+type Report struct {
+	// Results holds the outcome of checking each link.
+	Results []urlcheck.Result `json:"results"`
+	// Errors describes what could not be checked, such as files that could
+	// not be fetched, in which case the Report is incomplete.
+	Errors []string `json:"errors,omitempty"`
+	// Known holds the broken links already recorded within a baseline,
+	// which are not counted as broken.
+	Known []urlcheck.Result `json:"known,omitempty"`
+	// Fixed holds the links recorded within a baseline that are no longer
+	// broken.
+	Fixed []urlcheck.Result `json:"fixed,omitempty"`
+}
+
+// New is a wrapper for the creation of a Report type.
+func New(results []urlcheck.Result) *Report {
+	return &Report{Results: results}
+}
+
+// Read decodes a Report that has previously been saved in the json format.
+func Read(r io.Reader) (*Report, error) {
+	var rep Report
+	if err := json.NewDecoder(r).Decode(&rep); err != nil {
+		return nil, fmt.Errorf("decoding results: %w", err)
+	}
+	return &rep, nil
+}
+
+// Broken returns only the results of links that are broken, other than
+// those already known from a baseline.
+func (rep *Report) Broken() []urlcheck.Result {
+	var broken []urlcheck.Result
+	for _, result := range rep.Results {
+		if result.Broken() {
+			broken = append(broken, result)
+		}
+	}
+	return broken
+}
+
+// Suggestions returns the results of links that work, but for which a better
+// URL has been suggested.
+func (rep *Report) Suggestions() []urlcheck.Result {
+	var suggested []urlcheck.Result
+	for _, result := range rep.Results {
+		if result.Suggestion != "" && !result.Broken() {
+			suggested = append(suggested, result)
+		}
+	}
+	return suggested
+}
+
+// RepositorySummary counts the links checked within a single repository.
+type RepositorySummary struct {
+	// Repository is the owner and name of the repository, e.g., jwhitt3r/m-check.
+	Repository string
+	// Checked is the number of links that have been checked.
+	Checked int
+	// Broken is the number of links that are broken.
+	Broken int
+}
+
+// Repositories breaks the results down by the repository the links were
+// found in, ordered by name. Results of local files are not counted.
+func (rep *Report) Repositories() []RepositorySummary {
+	var summaries []RepositorySummary
+	index := make(map[string]int)
+	for _, result := range rep.Results {
+		if result.Repository == "" {
+			continue
+		}
+		i, ok := index[result.Repository]
+		if !ok {
+			i = len(summaries)
+			index[result.Repository] = i
+			summaries = append(summaries, RepositorySummary{Repository: result.Repository})
+		}
+		summaries[i].Checked++
+		if result.Broken() {
+			summaries[i].Broken++
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Repository < summaries[j].Repository
+	})
+	return summaries
+}
+
+// Write renders the Report in the named format.
+func (rep *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text", "":
+		return rep.writeText(w)
+	case "json":
+		return rep.writeJSON(w)
+	case "markdown":
+		return rep.writeMarkdown(w)
+	}
+	return fmt.Errorf("unknown report format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// writeText renders each result on its own line, in the same form as the
+// output.txt file.
+func (rep *Report) writeText(w io.Writer) error {
+	for _, result := range rep.Results {
+		if _, err := fmt.Fprintln(w, result); err != nil {
+			return err
+		}
+	}
+	return rep.writeTextNotes(w)
+}
+
+// writeTextNotes renders what follows the results in the text format: the
+// suggestions, the links known from and fixed since a baseline, and what
+// could not be checked.
+func (rep *Report) writeTextNotes(w io.Writer) error {
+	for _, result := range rep.Suggestions() {
+		if _, err := fmt.Fprintf(w, "Suggestion: %s - %s\n", result.URL, result.Suggestion); err != nil {
+			return err
+		}
+	}
+	for _, result := range rep.Known {
+		if _, err := fmt.Fprintf(w, "Known: %s\n", result); err != nil {
+			return err
+		}
+	}
+	for _, result := range rep.Fixed {
+		if _, err := fmt.Fprintf(w, "Fixed: %s - %s\n", result.URL, Location(result)); err != nil {
+			return err
+		}
+	}
+	for _, msg := range rep.Errors {
+		if _, err := fmt.Fprintf(w, "Incomplete: %s\n", msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TextStream writes a Report in the text format progressively, writing each
+// result as soon as it has been checked, and the rest of the Report once
+// every link has been checked.
+type TextStream struct {
+	w io.Writer
+}
+
+// NewTextStream is a wrapper for the creation of a TextStream type.
+func NewTextStream(w io.Writer) *TextStream {
+	return &TextStream{w: w}
+}
+
+// Result writes a single result.
+func (s *TextStream) Result(result urlcheck.Result) error {
+	_, err := fmt.Fprintln(s.w, result)
+	return err
+}
+
+// Close writes what follows the results of the Report, whose results have
+// each already been written by Result.
+func (s *TextStream) Close(rep *Report) error {
+	return rep.writeTextNotes(s.w)
+}
+
+// writeJSON renders the Report so that it can be read back in by Read.
+func (rep *Report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+// writeMarkdown renders the broken links of the Report as a markdown table,
+// followed by the links that have a suggested permalink and the links of
+// the baseline that have been fixed.
+func (rep *Report) writeMarkdown(w io.Writer) error {
+	broken := rep.Broken()
+	fmt.Fprintf(w, "## m-check results\n\n%d links checked, %d broken", len(rep.Results)+len(rep.Known), len(broken))
+	if len(rep.Known) > 0 {
+		fmt.Fprintf(w, ", %d more already known from the baseline", len(rep.Known))
+	}
+	fmt.Fprintln(w, ".")
+	for _, msg := range rep.Errors {
+		fmt.Fprintf(w, "\n> **Incomplete:** %s\n", msg)
+	}
+
+	if summaries := rep.Repositories(); len(summaries) > 0 {
+		fmt.Fprintf(w, "\n| Repository | Links | Broken |\n| --- | --- | --- |\n")
+		for _, summary := range summaries {
+			fmt.Fprintf(w, "| %s | %d | %d |\n", summary.Repository, summary.Checked, summary.Broken)
+		}
+	}
+
+	if len(broken) > 0 {
+		fmt.Fprintf(w, "\n| Location | Link | Status |\n| --- | --- | --- |\n")
+		for _, result := range broken {
+			_, err := fmt.Fprintf(w, "| %s | %s | %s |\n", Location(result), escape(result.URL), escape(Status(result)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if suggested := rep.Suggestions(); len(suggested) > 0 {
+		fmt.Fprintf(w, "\n### Suggested permalinks\n\n| Location | Link | Permalink |\n| --- | --- | --- |\n")
+		for _, result := range suggested {
+			_, err := fmt.Fprintf(w, "| %s | %s | %s |\n", Location(result), escape(result.URL), escape(result.Suggestion))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(rep.Fixed) > 0 {
+		fmt.Fprintf(w, "\n### Fixed since the baseline\n\n| Location | Link |\n| --- | --- |\n")
+		for _, result := range rep.Fixed {
+			if _, err := fmt.Fprintf(w, "| %s | %s |\n", Location(result), escape(result.URL)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Location formats where the link of a result was found as file:line,
+// prefixed by the repository holding the file when there is one. Links found
+// within an issue, pull request, release or discussion are located by its
+// URL alone.
+func Location(result urlcheck.Result) string {
+	location := result.File
+	if strings.Contains(location, "://") {
+		return location
+	}
+	if result.Repository != "" {
+		location = result.Repository + "/" + location
+	}
+	if result.Line == 0 {
+		return location
+	}
+	return fmt.Sprintf("%s:%d", location, result.Line)
+}
+
+// Status formats the status code of a result, along with the reason it is
+// broken when there is one, or its error when a connection could not be made.
+func Status(result urlcheck.Result) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	if result.Reason != "" {
+		return fmt.Sprintf("%d, %s", result.StatusCode, result.Reason)
+	}
+	return fmt.Sprint(result.StatusCode)
+}
+
+// escape prevents text from breaking out of a markdown table cell.
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
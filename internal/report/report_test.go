@@ -0,0 +1,120 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestReadWrite(t *testing.T) {
+	rep := New([]urlcheck.Result{
+		{Link: markdown.Link{URL: "https://github.com/jwhitt3r", File: "README.md", Line: 2}, StatusCode: 200},
+		{Link: markdown.Link{URL: "https://github.com/jwhitt3rasdasdasd3", File: "README.md", Line: 4}, StatusCode: 404},
+		{Link: markdown.Link{URL: "https://askldjalskdjlkasdjlskadjlkas.com", File: "docs/a.md", Line: 1}, Error: "no such host"},
+	})
+
+	t.Log("Given the need to save results and render them later on")
+	{
+		var buf bytes.Buffer
+		if err := rep.Write(&buf, "json"); err != nil {
+			t.Fatalf("\t%s\tShould be able to write the results as json : %v", failure, err)
+		}
+		t.Logf("\t%s\tShould be able to write the results as json.", success)
+
+		saved, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to read the saved results : %v", failure, err)
+		}
+		t.Logf("\t%s\tShould be able to read the saved results.", success)
+
+		if len(saved.Broken()) == 2 {
+			t.Logf("\t%s\tShould find 2 broken links.", success)
+		} else {
+			t.Errorf("\t%s\tShould find 2 broken links : %d", failure, len(saved.Broken()))
+		}
+
+		buf.Reset()
+		if err := saved.Write(&buf, "markdown"); err != nil {
+			t.Fatalf("\t%s\tShould be able to render the results as markdown : %v", failure, err)
+		}
+		if strings.Contains(buf.String(), "| README.md:4 | https://github.com/jwhitt3rasdasdasd3 | 404 |") {
+			t.Logf("\t%s\tShould render the location and status of a broken link.", success)
+		} else {
+			t.Errorf("\t%s\tShould render the location and status of a broken link : %s", failure, buf.String())
+		}
+
+		if err := saved.Write(&buf, "yaml"); err != nil {
+			t.Logf("\t%s\tShould reject an unknown format.", success)
+		} else {
+			t.Errorf("\t%s\tShould reject an unknown format.", failure)
+		}
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	rep := New(nil)
+	rep.Errors = []string{"jwhitt3r/m-check: GitHub API rate limit exhausted"}
+
+	t.Log("Given the need to never mistake an incomplete check for a clean one")
+	for testID, format := range []string{"text", "markdown", "json"} {
+		t.Logf("Test %d:\tWhen rendering the results as %s", testID, format)
+		var buf bytes.Buffer
+		if err := rep.Write(&buf, format); err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to render the results : %v", failure, testID, err)
+		}
+		if strings.Contains(buf.String(), "rate limit exhausted") {
+			t.Logf("\t%s\tTest %d:\tShould render why the check is incomplete.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould render why the check is incomplete : %s", failure, testID, buf.String())
+		}
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	rep := New([]urlcheck.Result{
+		{Link: markdown.Link{URL: "https://github.com/jwhitt3r/m-check/blob/main/go.mod#L3", File: "README.md", Line: 5}, StatusCode: 200, Suggestion: "https://github.com/jwhitt3r/m-check/blob/0a1b2c3/go.mod#L3"},
+		{Link: markdown.Link{URL: "https://github.com/jwhitt3r", File: "README.md", Line: 2}, StatusCode: 200},
+	})
+
+	t.Log("Given the need to suggest permalinks for links to branches")
+	{
+		var buf bytes.Buffer
+		if err := rep.Write(&buf, "markdown"); err != nil {
+			t.Fatalf("\t%s\tShould be able to render the results as markdown : %v", failure, err)
+		}
+		if strings.Contains(buf.String(), "| README.md:5 | https://github.com/jwhitt3r/m-check/blob/main/go.mod#L3 | https://github.com/jwhitt3r/m-check/blob/0a1b2c3/go.mod#L3 |") {
+			t.Logf("\t%s\tShould render the suggested permalink.", success)
+		} else {
+			t.Errorf("\t%s\tShould render the suggested permalink : %s", failure, buf.String())
+		}
+	}
+}
+
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		result urlcheck.Result
+		want   string
+	}{
+		{urlcheck.Result{Link: markdown.Link{File: "README.md", Line: 2}}, "README.md:2"},
+		{urlcheck.Result{Link: markdown.Link{Repository: "jwhitt3r/m-check", File: "docs/a.md", Line: 7}}, "jwhitt3r/m-check/docs/a.md:7"},
+		{urlcheck.Result{Link: markdown.Link{Repository: "jwhitt3r/m-check", File: "https://github.com/jwhitt3r/m-check/issues/3"}}, "https://github.com/jwhitt3r/m-check/issues/3"},
+	}
+
+	t.Log("Given the need to report where each link was found")
+	{
+		for i, tt := range tests {
+			t.Logf("\tTest %d:\tWhen locating %q", i, tt.want)
+			if got := Location(tt.result); got == tt.want {
+				t.Logf("\t%s\tTest %d:\tShould locate the link.", success, i)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould locate the link : %q", failure, i, got)
+			}
+		}
+	}
+}
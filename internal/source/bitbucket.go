@@ -0,0 +1,155 @@
+package source
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// BitbucketURL is the base URL of the Bitbucket Cloud API.
+const BitbucketURL = "https://api.bitbucket.org/2.0"
+
+// Bitbucket lists and fetches the files of a repository hosted on Bitbucket
+// Cloud through the REST API.
+type Bitbucket struct {
+	baseURL   string
+	workspace string
+	reponame  string
+	client    client
+	// mu guards mainBranchName.
+	mu sync.Mutex
+	// mainBranchName remembers the main branch of the repository once it
+	// has been looked up.
+	mainBranchName string
+}
+
+// NewBitbucket is a wrapper for the creation of a Bitbucket type. The baseURL
+// defaults to BitbucketURL. A token of the form username:app-password is sent
+// with basic authentication, any other token as a bearer access token.
+func NewBitbucket(baseURL string, token string, workspace string, reponame string) *Bitbucket {
+	if baseURL == "" {
+		baseURL = BitbucketURL
+	}
+	b := Bitbucket{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		workspace: workspace,
+		reponame:  reponame,
+	}
+	if token != "" {
+		b.client.auth = func(req *http.Request) {
+			if i := strings.Index(token, ":"); i >= 0 {
+				req.SetBasicAuth(token[:i], token[i+1:])
+				return
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return &b
+}
+
+// Name returns the full name of the repository, e.g., jwhitt3r/m-check.
+func (b *Bitbucket) Name() string {
+	return b.workspace + "/" + b.reponame
+}
+
+// repoURL returns the API URL of the repository.
+func (b *Bitbucket) repoURL() string {
+	return b.baseURL + "/repositories/" + url.PathEscape(b.workspace) + "/" + url.PathEscape(b.reponame)
+}
+
+// mainBranch returns the ref, or the name of the main branch of the
+// repository when the ref is empty, which is only looked up once.
+func (b *Bitbucket) mainBranch(ctx context.Context, ref string) (string, error) {
+	if ref != "" {
+		return ref, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.mainBranchName != "" {
+		return b.mainBranchName, nil
+	}
+	var repository struct {
+		MainBranch struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if _, err := b.client.getJSON(ctx, b.repoURL(), &repository); err != nil {
+		return "", err
+	}
+	b.mainBranchName = repository.MainBranch.Name
+	return b.mainBranchName, nil
+}
+
+// srcURL returns the API URL of the path at the ref.
+func (b *Bitbucket) srcURL(path string, ref string) string {
+	srcURL := b.repoURL() + "/src/" + url.PathEscape(ref) + "/"
+	if path = strings.Trim(path, "/"); path != "" {
+		srcURL += escapePath(path)
+	}
+	return srcURL
+}
+
+// List recursively looks through any directory within the path of the
+// repository and returns every file found. A directory that cannot be listed
+// does not stop the others from being listed, and is reported through a
+// *PartialError returned along with the files found.
+func (b *Bitbucket) List(ctx context.Context, path string, ref string) ([]string, error) {
+	ref, err := b.mainBranch(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return b.list(ctx, path, ref)
+}
+
+// list returns every file found within the path at the ref, following each
+// page of the response.
+func (b *Bitbucket) list(ctx context.Context, path string, ref string) ([]string, error) {
+	var files []string
+	var errs []error
+	next := strings.TrimSuffix(b.srcURL(path, ref), "/") + "/?pagelen=100"
+	for next != "" {
+		var page struct {
+			Values []struct {
+				Type string `json:"type"`
+				Path string `json:"path"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if _, err := b.client.getJSON(ctx, next, &page); err != nil {
+			if len(files) == 0 && len(errs) == 0 {
+				return nil, err
+			}
+			// The pages that follow cannot be found without this one.
+			errs = appendListError(errs, path, err)
+			break
+		}
+		for _, value := range page.Values {
+			switch value.Type {
+			case "commit_file":
+				files = append(files, value.Path)
+			case "commit_directory":
+				found, err := b.list(ctx, value.Path, ref)
+				files = append(files, found...)
+				errs = appendListError(errs, value.Path, err)
+			}
+		}
+		next = page.Next
+	}
+	return files, partial(errs)
+}
+
+// Fetch returns the raw contents of the file.
+func (b *Bitbucket) Fetch(ctx context.Context, path string, ref string) (io.ReadCloser, error) {
+	ref, err := b.mainBranch(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.client.get(ctx, b.srcURL(path, ref))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
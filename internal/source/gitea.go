@@ -0,0 +1,99 @@
+package source
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GiteaURL is the base URL of the Gitea.com API.
+const GiteaURL = "https://gitea.com/api/v1"
+
+// Gitea lists and fetches the files of a repository hosted on Gitea.com, or
+// on a self-hosted Gitea or Forgejo instance, through the REST API.
+type Gitea struct {
+	baseURL  string
+	owner    string
+	reponame string
+	client   client
+}
+
+// NewGitea is a wrapper for the creation of a Gitea type. The baseURL
+// defaults to GiteaURL, and the token, when set, is sent as an access token.
+func NewGitea(baseURL string, token string, owner string, reponame string) *Gitea {
+	if baseURL == "" {
+		baseURL = GiteaURL
+	}
+	g := Gitea{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		owner:    owner,
+		reponame: reponame,
+	}
+	if token != "" {
+		g.client.auth = func(req *http.Request) {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	}
+	return &g
+}
+
+// Name returns the full name of the repository, e.g., jwhitt3r/m-check.
+func (g *Gitea) Name() string {
+	return g.owner + "/" + g.reponame
+}
+
+// repoURL returns the API URL of the repository.
+func (g *Gitea) repoURL() string {
+	return g.baseURL + "/repos/" + url.PathEscape(g.owner) + "/" + url.PathEscape(g.reponame)
+}
+
+// List recursively looks through any directory within the path of the
+// repository and returns every file found. A directory that cannot be listed
+// does not stop the others from being listed, and is reported through a
+// *PartialError returned along with the files found.
+func (g *Gitea) List(ctx context.Context, path string, ref string) ([]string, error) {
+	contentsURL := g.repoURL() + "/contents"
+	if path = strings.Trim(path, "/"); path != "" {
+		contentsURL += "/" + escapePath(path)
+	}
+	if ref != "" {
+		contentsURL += "?ref=" + url.QueryEscape(ref)
+	}
+
+	var entries []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	}
+	if _, err := g.client.getJSON(ctx, contentsURL, &entries); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var errs []error
+	for _, entry := range entries {
+		switch entry.Type {
+		case "file":
+			files = append(files, entry.Path)
+		case "dir":
+			found, err := g.List(ctx, entry.Path, ref)
+			files = append(files, found...)
+			errs = appendListError(errs, entry.Path, err)
+		}
+	}
+	return files, partial(errs)
+}
+
+// Fetch returns the raw contents of the file.
+func (g *Gitea) Fetch(ctx context.Context, path string, ref string) (io.ReadCloser, error) {
+	rawURL := g.repoURL() + "/raw/" + escapePath(path)
+	if ref != "" {
+		rawURL += "?ref=" + url.QueryEscape(ref)
+	}
+	resp, err := g.client.get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,101 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/jwhitt3r/m-check/internal/repo"
+)
+
+// GitHub lists and fetches the files of a repository hosted on GitHub, or
+// on a GitHub Enterprise Server instance, through a connected Repository.
+type GitHub struct {
+	repo *repo.Repository
+	// mu guards downloads.
+	mu sync.Mutex
+	// downloads remembers the download URL of each file that has been listed,
+	// keyed by ref and path, so that fetching it needs no further API calls.
+	downloads map[string]string
+}
+
+// NewGitHub is a wrapper for the creation of a GitHub type from a Repository
+// that has already been connected by NewGithubConnection.
+func NewGitHub(r *repo.Repository) *GitHub {
+	return &GitHub{
+		repo:      r,
+		downloads: make(map[string]string),
+	}
+}
+
+// Repository returns the connected Repository the files are listed from.
+func (g *GitHub) Repository() *repo.Repository {
+	return g.repo
+}
+
+// Name returns the full name of the repository, e.g., jwhitt3r/m-check.
+func (g *GitHub) Name() string {
+	return g.repo.FullName()
+}
+
+// List recursively looks through any directory within the path of the
+// repository and returns every file found. A directory that cannot be listed
+// does not stop the others from being listed, and is reported through a
+// *PartialError returned along with the files found.
+func (g *GitHub) List(ctx context.Context, path string, ref string) ([]string, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	_, dirContents, _, err := g.repo.Client().Repositories.GetContents(ctx, g.repo.Owner, g.repo.RepoName, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var errs []error
+	for _, element := range dirContents {
+		switch element.GetType() {
+		case "file":
+			g.mu.Lock()
+			g.downloads[ref+":"+element.GetPath()] = element.GetDownloadURL()
+			g.mu.Unlock()
+			files = append(files, element.GetPath())
+		case "dir":
+			found, err := g.List(ctx, element.GetPath(), ref)
+			files = append(files, found...)
+			errs = appendListError(errs, element.GetPath(), err)
+		}
+	}
+	return files, partial(errs)
+}
+
+// Fetch downloads the raw contents of the file, authenticating with the
+// token of the Repository.
+func (g *GitHub) Fetch(ctx context.Context, path string, ref string) (io.ReadCloser, error) {
+	g.mu.Lock()
+	downloadURL, ok := g.downloads[ref+":"+path]
+	g.mu.Unlock()
+	if !ok {
+		opts := &github.RepositoryContentGetOptions{Ref: ref}
+		file, _, _, err := g.repo.Client().Repositories.GetContents(ctx, g.repo.Owner, g.repo.RepoName, path, opts)
+		if err != nil {
+			return nil, err
+		}
+		downloadURL = file.GetDownloadURL()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.repo.HTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", path, resp.Status)
+	}
+	return resp.Body, nil
+}
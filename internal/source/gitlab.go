@@ -0,0 +1,134 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// GitLabURL is the base URL of the GitLab.com API.
+const GitLabURL = "https://gitlab.com/api/v4"
+
+// GitLab lists and fetches the files of a project hosted on GitLab.com, or
+// on a self-managed GitLab instance, through the REST API.
+type GitLab struct {
+	baseURL string
+	project string
+	client  client
+	// mu guards defaultBranch.
+	mu sync.Mutex
+	// defaultBranch remembers the default branch of the project once it
+	// has been looked up.
+	defaultBranch string
+}
+
+// NewGitLab is a wrapper for the creation of a GitLab type. The baseURL
+// defaults to GitLabURL, and the token, when set, is sent as a private token.
+// The owner may hold nested groups, e.g., group/subgroup.
+func NewGitLab(baseURL string, token string, owner string, reponame string) *GitLab {
+	if baseURL == "" {
+		baseURL = GitLabURL
+	}
+	g := GitLab{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		project: owner + "/" + reponame,
+	}
+	if token != "" {
+		g.client.auth = func(req *http.Request) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		}
+	}
+	return &g
+}
+
+// Name returns the full path of the project, e.g., jwhitt3r/m-check.
+func (g *GitLab) Name() string {
+	return g.project
+}
+
+// projectURL returns the API URL of the project.
+func (g *GitLab) projectURL() string {
+	return g.baseURL + "/projects/" + url.PathEscape(g.project)
+}
+
+// List returns every file within the path of the repository by walking its
+// tree recursively, following each page of the response. A page that cannot
+// be listed is reported through a *PartialError returned along with the files
+// of the pages before it.
+func (g *GitLab) List(ctx context.Context, path string, ref string) ([]string, error) {
+	query := url.Values{}
+	query.Set("recursive", "true")
+	query.Set("per_page", "100")
+	if path = strings.Trim(path, "/"); path != "" {
+		query.Set("path", path)
+	}
+	if ref != "" {
+		query.Set("ref", ref)
+	}
+
+	var files []string
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		var entries []struct {
+			Type string `json:"type"`
+			Path string `json:"path"`
+		}
+		resp, err := g.client.getJSON(ctx, g.projectURL()+"/repository/tree?"+query.Encode(), &entries)
+		if err != nil {
+			if len(files) == 0 {
+				return nil, err
+			}
+			// The pages that follow cannot be found without this one.
+			return files, partial(appendListError(nil, path, err))
+		}
+		for _, entry := range entries {
+			if entry.Type == "blob" {
+				files = append(files, entry.Path)
+			}
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return files, nil
+}
+
+// branch returns the ref, or the default branch of the project when the ref
+// is empty, which is only looked up once.
+func (g *GitLab) branch(ctx context.Context, ref string) (string, error) {
+	if ref != "" {
+		return ref, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.defaultBranch != "" {
+		return g.defaultBranch, nil
+	}
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if _, err := g.client.getJSON(ctx, g.projectURL(), &project); err != nil {
+		return "", err
+	}
+	g.defaultBranch = project.DefaultBranch
+	return g.defaultBranch, nil
+}
+
+// Fetch returns the raw contents of the file. Without a ref, the default
+// branch of the project is used.
+func (g *GitLab) Fetch(ctx context.Context, path string, ref string) (io.ReadCloser, error) {
+	ref, err := g.branch(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	fileURL := fmt.Sprintf("%s/repository/files/%s/raw?ref=%s",
+		g.projectURL(), url.PathEscape(strings.Trim(path, "/")), url.QueryEscape(ref))
+	resp, err := g.client.get(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
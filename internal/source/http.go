@@ -0,0 +1,68 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// client performs the authenticated requests made to the API of a provider.
+type client struct {
+	http *http.Client
+	// auth sets the credentials of a request.
+	auth func(req *http.Request)
+}
+
+// get requests the URL, returning the response when it has succeeded.
+func (c *client) get(ctx context.Context, rawurl string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.auth != nil {
+		c.auth(req)
+	}
+
+	hc := c.http
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s %s", rawurl, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// getJSON requests the URL and decodes the JSON response into v, returning
+// the response so that its headers can be read.
+func (c *client) getJSON(ctx context.Context, rawurl string, v interface{}) (*http.Response, error) {
+	resp, err := c.get(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", rawurl, err)
+	}
+	return resp, nil
+}
+
+// escapePath escapes every segment of a slash separated path.
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
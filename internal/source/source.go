@@ -0,0 +1,222 @@
+// Package source lists and fetches the markdown files of repositories
+// hosted by different source code hosting services, such as GitHub,
+// GitLab, Gitea and Bitbucket.
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+	"github.com/jwhitt3r/m-check/internal/platform/gitignore"
+	"github.com/jwhitt3r/m-check/internal/repo"
+)
+
+// Providers lists the names of every provider that can be selected.
+var Providers = []string{"github", "gitlab", "gitea", "bitbucket"}
+
+// Provider lists and fetches the files of a single repository hosted by a
+// source code hosting service.
+type Provider interface {
+	// Name returns the full name of the repository, e.g., jwhitt3r/m-check.
+	Name() string
+	// List returns the slash separated path of every file found under the
+	// path, recursively, at the ref. An empty ref uses the default branch.
+	List(ctx context.Context, path string, ref string) ([]string, error)
+	// Fetch returns the contents of the file found at the path at the ref.
+	// An empty ref uses the default branch.
+	Fetch(ctx context.Context, path string, ref string) (io.ReadCloser, error)
+}
+
+// Options holds what is needed to connect to a repository of any provider.
+type Options struct {
+	// Provider is the name of the provider, by default github.
+	Provider string
+	// BaseURL is the base URL of the provider's API, which is only needed
+	// for self-hosted instances, e.g., https://gitlab.example.com/api/v4.
+	BaseURL string
+	// UploadURL is the upload URL of a GitHub Enterprise Server instance.
+	UploadURL string
+	// Token authenticates with the provider.
+	Token string
+	// Owner is the owner, group or workspace holding the repository.
+	Owner string
+	// Repo is the name of the repository.
+	Repo string
+
+	// The following only apply to GitHub.
+
+	// MaxWait is the longest to wait for the rate limit of the GitHub API to
+	// reset once it has been exhausted, where zero fails straight away.
+	MaxWait time.Duration
+	// App authenticates as an installation of a GitHub App in place of the
+	// token when set.
+	App *repo.App
+	// Responses saves the responses of the GitHub API, so that they are
+	// requested conditionally when requested again.
+	Responses *repo.ResponseCache
+}
+
+// New connects to the repository described by the Options.
+func New(opts Options) (Provider, error) {
+	switch opts.Provider {
+	case "github", "":
+		r := repo.NewRepository(opts.Owner, opts.Repo, opts.Token)
+		if opts.BaseURL != "" {
+			if err := r.UseEnterprise(opts.BaseURL, opts.UploadURL); err != nil {
+				return nil, err
+			}
+		}
+		if opts.App != nil {
+			r.UseApp(*opts.App)
+		}
+		if opts.Responses != nil {
+			r.UseResponseCache(opts.Responses)
+		}
+		r.WaitForRateLimit(opts.MaxWait)
+		r.NewGithubConnection()
+		return NewGitHub(r), nil
+	}
+
+	var p Provider
+	switch opts.Provider {
+	case "gitlab":
+		p = NewGitLab(opts.BaseURL, opts.Token, opts.Owner, opts.Repo)
+	case "gitea":
+		p = NewGitea(opts.BaseURL, opts.Token, opts.Owner, opts.Repo)
+	case "bitbucket":
+		p = NewBitbucket(opts.BaseURL, opts.Token, opts.Owner, opts.Repo)
+	default:
+		return nil, fmt.Errorf("unknown provider %q, expected one of %s", opts.Provider, strings.Join(Providers, ", "))
+	}
+	if opts.MaxWait != 0 || opts.App != nil || opts.Responses != nil {
+		return nil, fmt.Errorf("waiting for the rate limit, a GitHub App and the API cache only apply to GitHub, not %s", opts.Provider)
+	}
+	return p, nil
+}
+
+// Extract fetches every markdown file found under the path of the repository
+// at the ref, and returns the links found within them recorded against the
+// path of the file within the repository. A path naming a markdown file
+// extracts the links of that file alone.
+//
+// Files that cannot be listed or fetched do not stop the others from being
+// extracted; the links that were found are returned along with an error
+// describing what was missed. Once the context is cancelled, no further
+// files are fetched.
+func Extract(ctx context.Context, p Provider, path string, ref string) ([]markdown.Link, error) {
+	out := make(chan markdown.Link)
+	var err error
+	go func() {
+		err = Stream(ctx, p, path, ref, out)
+		close(out)
+	}()
+
+	var links []markdown.Link
+	for link := range out {
+		links = append(links, link)
+	}
+	return links, err
+}
+
+// Stream is Extract, sending each link to out as soon as its file has been
+// fetched, so that the links can be checked while the remaining files are
+// still being fetched. Out is left open for the caller to close.
+func Stream(ctx context.Context, p Provider, path string, ref string, out chan<- markdown.Link) error {
+	var errs []error
+	files := []string{path}
+	if !IsMarkdown(path) {
+		var err error
+		files, err = p.List(ctx, path, ref)
+		var listed *PartialError
+		switch {
+		case errors.As(err, &listed):
+			errs = append(errs, listed.Errors...)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("listing %s: %w", path, err))
+		}
+	}
+
+	for _, file := range files {
+		if !IsMarkdown(file) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		f, err := p.Fetch(ctx, file, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetching %s: %w", file, err))
+			continue
+		}
+		links := markdown.Extract(f, file)
+		f.Close()
+		for _, link := range links {
+			link.Repository = p.Name()
+			select {
+			case out <- link:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &PartialError{Errors: errs}
+	}
+	return nil
+}
+
+// PartialError is returned by Extract and Stream when some of the files of the
+// repository could not be listed or fetched, and by the List method of each
+// Provider when some of the directories could not be listed.
+type PartialError struct {
+	// Errors holds each failure.
+	Errors []error
+}
+
+func (e *PartialError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "incomplete, " + strings.Join(msgs, "; ")
+}
+
+// appendListError appends the error met listing the directory, or each error
+// held by a *PartialError returned from listing it, to errs.
+func appendListError(errs []error, dir string, err error) []error {
+	var listed *PartialError
+	switch {
+	case errors.As(err, &listed):
+		return append(errs, listed.Errors...)
+	case err != nil:
+		return append(errs, fmt.Errorf("listing %s: %w", dir, err))
+	}
+	return errs
+}
+
+// partial returns a *PartialError holding the errors, or nil when there are
+// none.
+func partial(errs []error) error {
+	if len(errs) > 0 {
+		return &PartialError{Errors: errs}
+	}
+	return nil
+}
+
+// IsMarkdown reports whether the path is of a markdown file.
+func IsMarkdown(path string) bool {
+	for _, glob := range directory.DefaultInclude {
+		if gitignore.Match(glob, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/repo"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestExtractGitHubEnterprise(t *testing.T) {
+	var authorization string
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[
+			{"type": "file", "name": "a.md", "path": "docs/a.md", "download_url": "%[1]s/raw/jwhitt3r/m-check/main/docs/a.md"},
+			{"type": "file", "name": "logo.png", "path": "docs/logo.png", "download_url": "%[1]s/raw/jwhitt3r/m-check/main/docs/logo.png"}
+		]`, srv.URL)
+	})
+	mux.HandleFunc("/raw/jwhitt3r/m-check/main/docs/a.md", func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, "[Jwhitt3rs Github](https://github.com/jwhitt3r)\n")
+	})
+
+	t.Log("Given the need to scan a GitHub Enterprise Server instance")
+	{
+		p, err := New(Options{BaseURL: srv.URL, Token: "12345", Owner: "jwhitt3r", Repo: "m-check"})
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to use the server URL : %v", failure, err)
+		}
+
+		links, err := Extract(context.Background(), p, "docs", "")
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to extract links through the enterprise API : %v", failure, err)
+		}
+		if len(links) == 1 && links[0].File == "docs/a.md" && links[0].Repository == "jwhitt3r/m-check" {
+			t.Logf("\t%s\tShould find the link within docs/a.md.", success)
+		} else {
+			t.Errorf("\t%s\tShould find the link within docs/a.md : %+v", failure, links)
+		}
+		if authorization == "Bearer 12345" {
+			t.Logf("\t%s\tShould authenticate downloads with the same token.", success)
+		} else {
+			t.Errorf("\t%s\tShould authenticate downloads with the same token : %q", failure, authorization)
+		}
+	}
+}
+
+func TestExtractProviders(t *testing.T) {
+	var authorization string
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	raw := func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization") + r.Header.Get("PRIVATE-TOKEN")
+		fmt.Fprint(w, "Read the [guide](https://example.com/guide).\n")
+	}
+
+	// GitLab paginates the tree of the project with the X-Next-Page header.
+	mux.HandleFunc("/gitlab/projects/group/sub/m-check", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"default_branch": "main"}`)
+	})
+	mux.HandleFunc("/gitlab/projects/group/sub/m-check/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"type": "tree", "path": "docs/guide"}, {"type": "blob", "path": "docs/logo.png"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"type": "blob", "path": "docs/guide/index.md"}]`)
+	})
+	mux.HandleFunc("/gitlab/projects/group/sub/m-check/repository/files/docs/guide/index.md/raw", raw)
+
+	// Gitea lists the contents of one directory at a time.
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"type": "dir", "path": "docs/guide"}, {"type": "file", "path": "docs/logo.png"}]`)
+	})
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/contents/docs/guide", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"type": "file", "path": "docs/guide/index.md"}]`)
+	})
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/raw/docs/guide/index.md", raw)
+
+	// Bitbucket lists the contents of one directory at a time, paginated by
+	// the next link.
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"mainbranch": {"name": "main"}}`)
+	})
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"values": [{"type": "commit_directory", "path": "docs/guide"}], "next": "%s/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/?page=2"}`, srv.URL)
+			return
+		}
+		fmt.Fprint(w, `{"values": [{"type": "commit_file", "path": "docs/logo.png"}]}`)
+	})
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/guide/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"values": [{"type": "commit_file", "path": "docs/guide/index.md"}]}`)
+	})
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/guide/index.md", raw)
+
+	tt := []struct {
+		opts          Options
+		authorization string
+	}{
+		{Options{Provider: "gitlab", BaseURL: srv.URL + "/gitlab", Token: "12345", Owner: "group/sub", Repo: "m-check"}, "12345"},
+		{Options{Provider: "gitea", BaseURL: srv.URL + "/gitea/", Token: "12345", Owner: "jwhitt3r", Repo: "m-check"}, "token 12345"},
+		{Options{Provider: "bitbucket", BaseURL: srv.URL + "/bitbucket", Token: "12345", Owner: "jwhitt3r", Repo: "m-check"}, "Bearer 12345"},
+		{Options{Provider: "bitbucket", BaseURL: srv.URL + "/bitbucket", Token: "user:secret", Owner: "jwhitt3r", Repo: "m-check"}, "Basic dXNlcjpzZWNyZXQ="},
+	}
+
+	t.Log("Given the need to scan repositories hosted outside of GitHub")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen extracting the links of a %s repository", testID, test.opts.Provider)
+		authorization = ""
+		p, err := New(test.opts)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to connect to the provider : %v", failure, testID, err)
+		}
+
+		links, err := Extract(context.Background(), p, "docs", "")
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to extract the links : %v", failure, testID, err)
+		}
+		if len(links) == 1 && links[0].File == "docs/guide/index.md" && links[0].Repository == test.opts.Owner+"/m-check" {
+			t.Logf("\t%s\tTest %d:\tShould find the link within docs/guide/index.md.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould find the link within docs/guide/index.md : %+v", failure, testID, links)
+		}
+		if authorization == test.authorization {
+			t.Logf("\t%s\tTest %d:\tShould authenticate with %q.", success, testID, test.authorization)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould authenticate with %q : %q", failure, testID, test.authorization, authorization)
+		}
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	t.Log("Given the need to reject providers that are not supported")
+	{
+		if _, err := New(Options{Provider: "sourceforge"}); err != nil {
+			t.Logf("\t%s\tShould return an error : %v", success, err)
+		} else {
+			t.Errorf("\t%s\tShould return an error.", failure)
+		}
+	}
+}
+
+func TestDefaultBranchLookedUpOnce(t *testing.T) {
+	var lookups int
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	raw := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "# Title\n")
+	}
+	mux.HandleFunc("/gitlab/projects/jwhitt3r/m-check", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		fmt.Fprint(w, `{"default_branch": "main"}`)
+	})
+	mux.HandleFunc("/gitlab/projects/jwhitt3r/m-check/repository/files/", raw)
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		fmt.Fprint(w, `{"mainbranch": {"name": "main"}}`)
+	})
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/", raw)
+
+	tt := []Options{
+		{Provider: "gitlab", BaseURL: srv.URL + "/gitlab", Owner: "jwhitt3r", Repo: "m-check"},
+		{Provider: "bitbucket", BaseURL: srv.URL + "/bitbucket", Owner: "jwhitt3r", Repo: "m-check"},
+	}
+
+	t.Log("Given the need to spend as few API calls as possible on the default branch")
+	for testID, opts := range tt {
+		t.Logf("Test %d:\tWhen fetching many files of a %s repository without a ref", testID, opts.Provider)
+		lookups = 0
+		p, err := New(opts)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to connect to the provider : %v", failure, testID, err)
+		}
+		for _, path := range []string{"docs/a.md", "docs/b.md", "docs/c.md"} {
+			f, err := p.Fetch(context.Background(), path, "")
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to fetch %s : %v", failure, testID, path, err)
+			}
+			f.Close()
+		}
+		if lookups == 1 {
+			t.Logf("\t%s\tTest %d:\tShould look the default branch up once.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould look the default branch up once : %d", failure, testID, lookups)
+		}
+	}
+}
+
+func TestExtractPartialListing(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	raw := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Read the [guide](https://example.com/guide).\n")
+	}
+	broken := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"type": "dir", "name": "broken", "path": "docs/broken"},
+			{"type": "dir", "name": "guide", "path": "docs/guide"}
+		]`)
+	})
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/contents/docs/broken", broken)
+	mux.HandleFunc("/api/v3/repos/jwhitt3r/m-check/contents/docs/guide", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"type": "file", "name": "index.md", "path": "docs/guide/index.md", "download_url": "%s/raw/docs/guide/index.md"}]`, srv.URL)
+	})
+	mux.HandleFunc("/raw/docs/guide/index.md", raw)
+
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"type": "dir", "path": "docs/broken"}, {"type": "dir", "path": "docs/guide"}]`)
+	})
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/contents/docs/broken", broken)
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/contents/docs/guide", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"type": "file", "path": "docs/guide/index.md"}]`)
+	})
+	mux.HandleFunc("/gitea/repos/jwhitt3r/m-check/raw/docs/guide/index.md", raw)
+
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"values": [{"type": "commit_directory", "path": "docs/broken"}, {"type": "commit_directory", "path": "docs/guide"}]}`)
+	})
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/broken/", broken)
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/guide/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"values": [{"type": "commit_file", "path": "docs/guide/index.md"}]}`)
+	})
+	mux.HandleFunc("/bitbucket/repositories/jwhitt3r/m-check/src/main/docs/guide/index.md", raw)
+
+	tt := []Options{
+		{Provider: "github", BaseURL: srv.URL, Owner: "jwhitt3r", Repo: "m-check"},
+		{Provider: "gitea", BaseURL: srv.URL + "/gitea", Owner: "jwhitt3r", Repo: "m-check"},
+		{Provider: "bitbucket", BaseURL: srv.URL + "/bitbucket", Owner: "jwhitt3r", Repo: "m-check"},
+	}
+
+	t.Log("Given a repository with a directory that cannot be listed")
+	for testID, opts := range tt {
+		t.Logf("Test %d:\tWhen extracting the links of a %s repository", testID, opts.Provider)
+		p, err := New(opts)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to connect to the provider : %v", failure, testID, err)
+		}
+
+		ref := ""
+		if opts.Provider == "bitbucket" {
+			ref = "main"
+		}
+		links, err := Extract(context.Background(), p, "docs", ref)
+		var partial *PartialError
+		if errors.As(err, &partial) && len(partial.Errors) == 1 && strings.Contains(partial.Errors[0].Error(), "listing docs/broken") {
+			t.Logf("\t%s\tTest %d:\tShould report the directory that could not be listed : %v", success, testID, err)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould report the directory that could not be listed : %v", failure, testID, err)
+		}
+		if len(links) == 1 && links[0].File == "docs/guide/index.md" {
+			t.Logf("\t%s\tTest %d:\tShould keep listing the other directories.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould keep listing the other directories : %+v", failure, testID, links)
+		}
+	}
+}
+
+func TestNewGitHubOnlyOptions(t *testing.T) {
+	tt := []struct {
+		opts Options
+		ok   bool
+	}{
+		{Options{Provider: "github", Owner: "jwhitt3r", Repo: "m-check", MaxWait: time.Minute, App: &repo.App{ID: 1}}, true},
+		{Options{Provider: "gitlab", Owner: "jwhitt3r", Repo: "m-check", MaxWait: time.Minute}, false},
+		{Options{Provider: "gitea", Owner: "jwhitt3r", Repo: "m-check", App: &repo.App{ID: 1}}, false},
+		{Options{Provider: "bitbucket", Owner: "jwhitt3r", Repo: "m-check"}, true},
+	}
+
+	t.Log("Given the need to only accept the options that apply to GitHub for GitHub")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen connecting to a %s repository", testID, test.opts.Provider)
+		if _, err := New(test.opts); (err == nil) == test.ok {
+			t.Logf("\t%s\tTest %d:\tShould accept the options: %v : %v", success, testID, test.ok, err)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould accept the options: %v : %v", failure, testID, test.ok, err)
+		}
+	}
+}
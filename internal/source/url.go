@@ -0,0 +1,144 @@
+package source
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// hosts maps the hosts of the public instances of each provider to the
+// name of the provider.
+var hosts = map[string]string{
+	"github.com":    "github",
+	"gitlab.com":    "gitlab",
+	"gitea.com":     "gitea",
+	"codeberg.org":  "gitea",
+	"bitbucket.org": "bitbucket",
+}
+
+// Location is a repository, and optionally a ref and path within it, parsed
+// from the URL of the repository as it is shown in a browser.
+type Location struct {
+	Options
+	// Ref is the branch, tag or commit named by the URL, if any.
+	Ref string
+	// Path is the path within the repository named by the URL, if any.
+	Path string
+}
+
+// ParseURL parses the URL of a repository, or of a directory or file within
+// it, such as https://github.com/jwhitt3r/m-check/tree/main/docs. The
+// provider is found from the host of the URL, unless the provider is given,
+// which is needed for self-hosted instances. As a ref may itself hold
+// slashes, the first segment following tree, blob or src is always taken
+// as the ref.
+func ParseURL(rawurl string, provider string) (Location, error) {
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "https://" + rawurl
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return Location{}, err
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if provider == "" {
+		hosted, ok := hosts[host]
+		if !ok {
+			return Location{}, fmt.Errorf("unknown host %q, the provider must be set for self-hosted instances", u.Host)
+		}
+		provider = hosted
+	}
+
+	var loc Location
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	instance := u.Scheme + "://" + u.Host
+	switch provider {
+	case "github":
+		loc, err = parseSegments(segments, "tree", "blob")
+		if host != "github.com" {
+			loc.BaseURL = instance + "/api/v3/"
+		}
+	case "gitlab":
+		loc, err = parseGitLab(segments)
+		if host != "gitlab.com" {
+			loc.BaseURL = instance + "/api/v4"
+		}
+	case "gitea":
+		// Gitea names the kind of ref before the ref itself, e.g.,
+		// src/branch/main/docs.
+		if len(segments) > 3 && segments[2] == "src" {
+			switch segments[3] {
+			case "branch", "tag", "commit":
+				segments = append(segments[:3:3], segments[4:]...)
+			}
+		}
+		loc, err = parseSegments(segments, "src")
+		if host != "gitea.com" {
+			loc.BaseURL = instance + "/api/v1"
+		}
+	case "bitbucket":
+		if host != "bitbucket.org" {
+			return Location{}, fmt.Errorf("only Bitbucket Cloud is supported, not %q", u.Host)
+		}
+		loc, err = parseSegments(segments, "src")
+	default:
+		return Location{}, fmt.Errorf("unknown provider %q, expected one of %s", provider, strings.Join(Providers, ", "))
+	}
+	if err != nil {
+		return Location{}, fmt.Errorf("parsing %s: %w", rawurl, err)
+	}
+	loc.Provider = provider
+	return loc, nil
+}
+
+// parseSegments parses the segments of a path of the form
+// owner/repo[/<kind>/ref[/path...]], where kind is one of the kinds given.
+func parseSegments(segments []string, kinds ...string) (Location, error) {
+	if len(segments) < 2 {
+		return Location{}, fmt.Errorf("expected the owner and name of the repository")
+	}
+
+	loc := Location{}
+	loc.Owner = segments[0]
+	loc.Repo = strings.TrimSuffix(segments[1], ".git")
+	if len(segments) < 4 {
+		return loc, nil
+	}
+	for _, kind := range kinds {
+		if segments[2] == kind {
+			loc.Ref = segments[3]
+			loc.Path = strings.Join(segments[4:], "/")
+			return loc, nil
+		}
+	}
+	return Location{}, fmt.Errorf("unexpected %q following the repository", segments[2])
+}
+
+// parseGitLab parses the segments of a path of the form
+// group[/subgroup...]/project[/-/tree/ref[/path...]], where the project may
+// be nested within many groups.
+func parseGitLab(segments []string) (Location, error) {
+	project := segments
+	var rest []string
+	for i, segment := range segments {
+		if segment == "-" {
+			project, rest = segments[:i], segments[i+1:]
+			break
+		}
+	}
+	if len(project) < 2 {
+		return Location{}, fmt.Errorf("expected the group and name of the project")
+	}
+
+	loc := Location{}
+	loc.Owner = strings.Join(project[:len(project)-1], "/")
+	loc.Repo = strings.TrimSuffix(project[len(project)-1], ".git")
+	if len(rest) >= 2 && (rest[0] == "tree" || rest[0] == "blob") {
+		loc.Ref = rest[1]
+		loc.Path = strings.Join(rest[2:], "/")
+	} else if len(rest) > 0 {
+		return Location{}, fmt.Errorf("unexpected %q following the project", strings.Join(rest, "/"))
+	}
+	return loc, nil
+}
@@ -0,0 +1,57 @@
+package source
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	tt := []struct {
+		url      string
+		provider string
+		loc      Location
+	}{
+		{"https://github.com/org/repo/tree/v2/docs", "", Location{Options{Provider: "github", Owner: "org", Repo: "repo"}, "v2", "docs"}},
+		{"https://github.com/org/repo/blob/main/docs/guide/index.md", "", Location{Options{Provider: "github", Owner: "org", Repo: "repo"}, "main", "docs/guide/index.md"}},
+		{"github.com/org/repo.git", "", Location{Options{Provider: "github", Owner: "org", Repo: "repo"}, "", ""}},
+		{"https://github.example.com/org/repo/tree/main/docs", "github", Location{Options{Provider: "github", BaseURL: "https://github.example.com/api/v3/", Owner: "org", Repo: "repo"}, "main", "docs"}},
+		{"https://gitlab.com/group/sub/project/-/tree/main/docs", "", Location{Options{Provider: "gitlab", Owner: "group/sub", Repo: "project"}, "main", "docs"}},
+		{"https://gitlab.example.com/group/project", "gitlab", Location{Options{Provider: "gitlab", BaseURL: "https://gitlab.example.com/api/v4", Owner: "group", Repo: "project"}, "", ""}},
+		{"https://codeberg.org/org/repo/src/branch/main/docs", "", Location{Options{Provider: "gitea", BaseURL: "https://codeberg.org/api/v1", Owner: "org", Repo: "repo"}, "main", "docs"}},
+		{"https://gitea.com/org/repo/src/tag/v1.0/docs/index.md", "", Location{Options{Provider: "gitea", Owner: "org", Repo: "repo"}, "v1.0", "docs/index.md"}},
+		{"https://bitbucket.org/workspace/repo/src/main/docs/", "", Location{Options{Provider: "bitbucket", Owner: "workspace", Repo: "repo"}, "main", "docs"}},
+	}
+
+	t.Log("Given the need to scan a repository from its URL")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen parsing %q", testID, test.url)
+		loc, err := ParseURL(test.url, test.provider)
+		if err != nil {
+			t.Fatalf("\t%s\tTest %d:\tShould be able to parse the URL : %v", failure, testID, err)
+		}
+		if loc == test.loc {
+			t.Logf("\t%s\tTest %d:\tShould find %+v", success, testID, test.loc)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould find %+v : %+v", failure, testID, test.loc, loc)
+		}
+	}
+}
+
+func TestParseURLInvalid(t *testing.T) {
+	tt := []struct {
+		url      string
+		provider string
+	}{
+		{"https://git.example.com/org/repo", ""},
+		{"https://github.com/org", ""},
+		{"https://github.com/org/repo/issues/1", ""},
+		{"https://bitbucket.example.com/org/repo", "bitbucket"},
+	}
+
+	t.Log("Given the need to reject URLs that do not name a repository")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen parsing %q", testID, test.url)
+		if _, err := ParseURL(test.url, test.provider); err != nil {
+			t.Logf("\t%s\tTest %d:\tShould return an error : %v", success, testID, err)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould return an error.", failure, testID)
+		}
+	}
+}
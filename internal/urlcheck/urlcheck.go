@@ -4,10 +4,14 @@
 package urlcheck
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"sync"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
 )
 
 // URLChecker represents a URL that is being used to verify the URI's status code
@@ -15,14 +19,293 @@ type URLChecker struct {
 	// Client specifies a http.Client type to be used to make GET requests
 	// within the URLCheck function.
 	client http.Client
+	// mu guards checked.
+	mu sync.Mutex
+	// checked remembers the outcome of each URL, so that a URL found many
+	// times, or within many repositories, is only requested once.
+	checked map[string]*outcome
+	// validators check the URLs they recognise in place of a GET request.
+	validators []Validator
+	// cache remembers outcomes across runs, when one has been set.
+	cache Cache
+}
+
+// outcome is the response to a request made to a single URL.
+type outcome struct {
+	once sync.Once
+	Verdict
+	// cancelled is set when the check was cut short by its context, in
+	// which case the outcome is forgotten rather than remembered.
+	cancelled bool
+}
+
+// Verdict is the outcome of validating a single URL.
+type Verdict struct {
+	// StatusCode is the HTTP status code that best describes the outcome.
+	StatusCode int
+	// Error holds the reason the URL could not be validated.
+	Error string
+	// Reason explains a broken link in more detail than its status code.
+	Reason string
+	// Suggestion is a better URL to link to in place of the URL, such as a
+	// permalink in place of a link to a branch that will change.
+	Suggestion string
+	// Redirect is the URL the request was finally redirected to, when the
+	// server redirected it.
+	Redirect string
+	// Permanent is set when every redirect on the way to Redirect was
+	// permanent, a 301 or 308, so that links can be updated to it.
+	Permanent bool
+}
+
+// Validator checks the URLs it recognises by some means other than a GET
+// request, such as through the API of the service hosting them.
+type Validator interface {
+	// Validate returns the Verdict of the URL, where ok is false when the
+	// URL is not recognised and should be requested instead.
+	Validate(ctx context.Context, rawurl string) (v Verdict, ok bool)
+}
+
+// Cache remembers the Verdict of each URL beyond the lifetime of a
+// URLChecker, such as on disk between runs.
+type Cache interface {
+	// Get returns the remembered Verdict of the URL, where ok is false when
+	// there is none that is recent enough to be used.
+	Get(rawurl string) (v Verdict, ok bool)
+	// Put remembers the Verdict of the URL.
+	Put(rawurl string, v Verdict)
 }
 
 // NewURLCheck is a wrapper for the creation of a URLChecker type
 // which returns the address of the newly created URLChecker type.
 func NewURLCheck(client *http.Client) *URLChecker {
 	return &URLChecker{
-		client: *client,
+		client:  *client,
+		checked: make(map[string]*outcome),
+	}
+}
+
+// Use adds a Validator, which is asked to validate each URL before it is
+// requested. Validators are asked in the order they were added.
+func (u *URLChecker) Use(v Validator) {
+	u.validators = append(u.validators, v)
+}
+
+// UseCache sets the Cache consulted before a URL is validated or requested,
+// and which is given the Verdict of each URL that is.
+func (u *URLChecker) UseCache(c Cache) {
+	u.cache = c
+}
+
+// Result holds the outcome of checking a single link found within the
+// markdown documentation.
+type Result struct {
+	// Link is the URL that has been checked and where it was found.
+	markdown.Link
+	// StatusCode is the HTTP status code returned by the server, which is
+	// left as zero when a connection could not be made.
+	StatusCode int `json:"status_code,omitempty"`
+	// Error holds the reason a connection could not be made to the URL.
+	Error string `json:"error,omitempty"`
+	// Reason explains why a link is broken when the status code alone does
+	// not, e.g., that the branch it points at has been deleted.
+	Reason string `json:"reason,omitempty"`
+	// Suggestion is a better URL to link to, even when the link works,
+	// e.g., a permalink in place of a link to lines of a file on a branch.
+	Suggestion string `json:"suggestion,omitempty"`
+	// Redirect is the URL the link was finally redirected to, when the
+	// server redirected it.
+	Redirect string `json:"redirect,omitempty"`
+	// Permanent is set when every redirect on the way to Redirect was
+	// permanent, a 301 or 308, so that the link can be updated to it.
+	Permanent bool `json:"permanent,omitempty"`
+}
+
+// Broken reports whether the link could not be reached, or whether the
+// server responded with a client or server error status code.
+func (r Result) Broken() bool {
+	return r.Error != "" || r.StatusCode >= http.StatusBadRequest
+}
+
+// String formats the Result as the link followed by its status code,
+// or "Broken Link" when a connection could not be made.
+func (r Result) String() string {
+	if r.Error != "" {
+		return fmt.Sprintf("%s - Broken Link", r.URL)
+	}
+	return fmt.Sprintf("%s - %s", r.URL, strconv.Itoa(r.StatusCode))
+}
+
+// Check makes a connection to a link found within the Markdown
+// documentation and returns the outcome as a Result. A URL that has
+// already been checked by the URLChecker, or is held within its Cache, is
+// not requested again, and a URL recognised by a Validator is not requested
+// at all.
+func (u *URLChecker) Check(link markdown.Link) Result {
+	result, _ := u.CheckContext(context.Background(), link)
+	return result
+}
+
+// CheckContext is Check with a context, which cancels the request. When the
+// check is cut short by the context, its error is returned and the outcome
+// is neither remembered nor cached.
+func (u *URLChecker) CheckContext(ctx context.Context, link markdown.Link) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{Link: link}, err
+	}
+
+	u.mu.Lock()
+	o, ok := u.checked[link.URL]
+	if !ok {
+		o = &outcome{}
+		u.checked[link.URL] = o
+	}
+	u.mu.Unlock()
+
+	o.once.Do(func() {
+		if u.cache != nil {
+			if verdict, ok := u.cache.Get(link.URL); ok {
+				o.Verdict = verdict
+				return
+			}
+		}
+		o.Verdict = u.validate(ctx, link.URL)
+		if o.Error != "" && ctx.Err() != nil {
+			o.cancelled = true
+			return
+		}
+		if u.cache != nil {
+			u.cache.Put(link.URL, o.Verdict)
+		}
+	})
+	if o.cancelled {
+		u.mu.Lock()
+		if u.checked[link.URL] == o {
+			delete(u.checked, link.URL)
+		}
+		u.mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return Result{Link: link}, err
+		}
+		return Result{Link: link}, context.Canceled
 	}
+	return Result{Link: link, StatusCode: o.StatusCode, Error: o.Error, Reason: o.Reason, Suggestion: o.Suggestion, Redirect: o.Redirect, Permanent: o.Permanent}, nil
+}
+
+// validate asks each Validator for the Verdict of the URL, falling back to
+// a GET request when none recognise it.
+func (u *URLChecker) validate(ctx context.Context, rawurl string) Verdict {
+	for _, v := range u.validators {
+		if verdict, ok := v.Validate(ctx, rawurl); ok {
+			return verdict
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return Verdict{Error: err.Error()}
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return Verdict{Error: err.Error()}
+	}
+	resp.Body.Close()
+	v := Verdict{StatusCode: resp.StatusCode}
+	if final := resp.Request.URL.String(); final != rawurl {
+		v.Redirect = final
+		v.Permanent = permanent(resp)
+	}
+	return v
+}
+
+// permanent reports whether every redirect followed on the way to the
+// response was permanent.
+func permanent(resp *http.Response) bool {
+	followed := false
+	for via := resp.Request.Response; via != nil; via = via.Request.Response {
+		if via.StatusCode != http.StatusMovedPermanently && via.StatusCode != http.StatusPermanentRedirect {
+			return false
+		}
+		followed = true
+	}
+	return followed
+}
+
+// CheckBatch takes a list of links and wraps a concurrent check
+// of each link found within the documentation, returning the
+// outcome of each check ordered by file and line.
+func (u *URLChecker) CheckBatch(links []markdown.Link) []Result {
+	results, _ := u.CheckBatchContext(context.Background(), links)
+	return results
+}
+
+// CheckBatchContext is CheckBatch with a context. When the context is
+// cancelled, or its deadline passes, no further requests are made and the
+// outcome of the links that were checked is returned along with the error
+// of the context.
+func (u *URLChecker) CheckBatchContext(ctx context.Context, links []markdown.Link) ([]Result, error) {
+	in := make(chan markdown.Link)
+	go func() {
+		defer close(in)
+		for _, link := range links {
+			in <- link
+		}
+	}()
+
+	var results []Result
+	for result := range u.Stream(ctx, in) {
+		results = append(results, result)
+	}
+	Sort(results)
+	return results, ctx.Err()
+}
+
+// Workers is the number of links Stream checks at once.
+const Workers = 64
+
+// Stream checks each link received from links as soon as it arrives, with
+// up to Workers links being checked at once, and sends its Result on the
+// returned channel as soon as it is known. Results are sent in the order
+// the checks complete. The channel is closed once links has been closed
+// and every check has completed, and the caller must receive from it until
+// then. Links received once the context is done are not checked.
+func (u *URLChecker) Stream(ctx context.Context, links <-chan markdown.Link) <-chan Result {
+	results := make(chan Result)
+	var wg sync.WaitGroup
+	wg.Add(Workers)
+	for i := 0; i < Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for link := range links {
+				if result, err := u.CheckContext(ctx, link); err == nil {
+					results <- result
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// Sort orders the results by the repository and file the link was found
+// in, then by the line it was found on, and then by the URL, so that links
+// sharing a line are always listed in the same order.
+func Sort(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Repository != results[j].Repository {
+			return results[i].Repository < results[j].Repository
+		}
+		if results[i].File != results[j].File {
+			return results[i].File < results[j].File
+		}
+		if results[i].Line != results[j].Line {
+			return results[i].Line < results[j].Line
+		}
+		return results[i].URL < results[j].URL
+	})
 }
 
 // URLCheck makes a connection to a url found within the
@@ -30,12 +313,7 @@ func NewURLCheck(client *http.Client) *URLChecker {
 // to be appended to a list of links and status codes to
 // be examined later on.
 func (u *URLChecker) URLCheck(link string) string {
-	resp, err := u.client.Get(link)
-	if err != nil {
-		return fmt.Sprintf("%s - Broken Link", link)
-	}
-	defer resp.Body.Close()
-	return fmt.Sprintf("%s - %s", link, strconv.Itoa(resp.StatusCode))
+	return u.Check(markdown.Link{URL: link}).String()
 }
 
 // URLCheckBatch takes a list of urls and wraps a concurrent
@@ -43,19 +321,12 @@ func (u *URLChecker) URLCheck(link string) string {
 // then returns a slice of the outcomes to be saved to file.
 func (u *URLChecker) URLCheckBatch(links []string) []string {
 	var webConnectionResponse []string
-	ch := make(chan string, len(links))
-	var wg sync.WaitGroup
-	wg.Add(len(links))
+	var batch []markdown.Link
 	for _, link := range links {
-		go func(link string) {
-			ch <- u.URLCheck(link)
-			wg.Done()
-		}(link)
-	}
-	wg.Wait()
-	close(ch)
-	for value := range ch {
-		webConnectionResponse = append(webConnectionResponse, value)
+		batch = append(batch, markdown.Link{URL: link})
+	}
+	for _, result := range u.CheckBatch(batch) {
+		webConnectionResponse = append(webConnectionResponse, result.String())
 	}
 	return webConnectionResponse
 }
@@ -1,10 +1,15 @@
 package urlcheck
 
 import (
+	"context"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
 )
 
 const success = "\u2713"
@@ -64,3 +69,270 @@ func TestURLCheckBrokenLink(t *testing.T) {
 		}
 	}
 }
+
+func TestURLCheckOnce(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	links := []markdown.Link{
+		{URL: srv.URL + "/a", Repository: "my-org/service-a", File: "docs/a.md", Line: 1},
+		{URL: srv.URL + "/a", Repository: "my-org/service-b", File: "docs/b.md", Line: 2},
+		{URL: srv.URL + "/a", Repository: "my-org/service-b", File: "docs/b.md", Line: 7},
+	}
+	checker := NewURLCheck(&http.Client{Timeout: time.Second})
+
+	t.Log("Given a URL found within many repositories")
+	{
+		results := checker.CheckBatch(links)
+		if atomic.LoadInt32(&requests) == 1 {
+			t.Logf("\t%s\tShould only request the URL once.", success)
+		} else {
+			t.Errorf("\t%s\tShould only request the URL once : %d", failure, requests)
+		}
+		for testID, result := range results {
+			if result.Link == links[testID] && result.StatusCode == http.StatusNotFound {
+				t.Logf("\t%s\tTest %d:\tShould report the outcome against %s:%d", success, testID, result.File, result.Line)
+			} else {
+				t.Errorf("\t%s\tTest %d:\tShould report the outcome against %s:%d : %+v", failure, testID, links[testID].File, links[testID].Line, result)
+			}
+		}
+	}
+}
+
+// validatorFunc adapts a function into a Validator.
+type validatorFunc func(rawurl string) (Verdict, bool)
+
+func (f validatorFunc) Validate(ctx context.Context, rawurl string) (Verdict, bool) {
+	return f(rawurl)
+}
+
+func TestURLCheckValidator(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	checker := NewURLCheck(&http.Client{Timeout: time.Second})
+	checker.Use(validatorFunc(func(rawurl string) (Verdict, bool) {
+		if rawurl != srv.URL+"/validated" {
+			return Verdict{}, false
+		}
+		return Verdict{StatusCode: http.StatusNotFound, Reason: "the branch has been deleted"}, true
+	}))
+
+	tt := []struct {
+		url      string
+		result   Result
+		requests int32
+	}{
+		{srv.URL + "/validated", Result{Link: markdown.Link{URL: srv.URL + "/validated"}, StatusCode: http.StatusNotFound, Reason: "the branch has been deleted"}, 0},
+		{srv.URL + "/requested", Result{Link: markdown.Link{URL: srv.URL + "/requested"}, StatusCode: http.StatusOK}, 1},
+	}
+
+	t.Log("Given a Validator that recognises some URLs")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen checking %q", testID, test.url)
+		result := checker.Check(markdown.Link{URL: test.url})
+		if result == test.result && atomic.LoadInt32(&requests) == test.requests {
+			t.Logf("\t%s\tTest %d:\tShould find %+v after %d requests.", success, testID, test.result, test.requests)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould find %+v after %d requests : %+v after %d", failure, testID, test.result, test.requests, result, requests)
+		}
+	}
+}
+
+// mapCache is a Cache held within a map.
+type mapCache map[string]Verdict
+
+func (c mapCache) Get(rawurl string) (Verdict, bool) {
+	v, ok := c[rawurl]
+	return v, ok
+}
+
+func (c mapCache) Put(rawurl string, v Verdict) {
+	c[rawurl] = v
+}
+
+func TestURLCheckCache(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	c := mapCache{srv.URL + "/cached": Verdict{StatusCode: http.StatusNotFound}}
+	checker := NewURLCheck(&http.Client{Timeout: time.Second})
+	checker.UseCache(c)
+
+	tt := []struct {
+		url      string
+		status   int
+		requests int32
+	}{
+		{srv.URL + "/cached", http.StatusNotFound, 0},
+		{srv.URL + "/requested", http.StatusOK, 1},
+	}
+
+	t.Log("Given a Cache holding the outcome of some URLs")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen checking %q", testID, test.url)
+		result := checker.Check(markdown.Link{URL: test.url})
+		if result.StatusCode == test.status && atomic.LoadInt32(&requests) == test.requests {
+			t.Logf("\t%s\tTest %d:\tShould find %d after %d requests.", success, testID, test.status, test.requests)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould find %d after %d requests : %d after %d", failure, testID, test.status, test.requests, result.StatusCode, requests)
+		}
+	}
+	if c[srv.URL+"/requested"].StatusCode == http.StatusOK {
+		t.Logf("\t%s\tShould remember the outcome of the requested URL.", success)
+	} else {
+		t.Errorf("\t%s\tShould remember the outcome of the requested URL : %+v", failure, c)
+	}
+}
+
+func TestURLCheckRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/new", http.StatusMovedPermanently))
+	mux.Handle("/older", http.RedirectHandler("/old", http.StatusPermanentRedirect))
+	mux.Handle("/moved", http.RedirectHandler("/old", http.StatusFound))
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tt := []struct {
+		url       string
+		redirect  string
+		permanent bool
+	}{
+		{srv.URL + "/old", srv.URL + "/new", true},
+		{srv.URL + "/older", srv.URL + "/new", true},
+		{srv.URL + "/moved", srv.URL + "/new", false},
+		{srv.URL + "/new", "", false},
+	}
+
+	checker := NewURLCheck(&http.Client{Timeout: time.Second})
+	t.Log("Given the need to know which links are redirected")
+	for testID, test := range tt {
+		t.Logf("Test %d:\tWhen checking %q", testID, test.url)
+		result := checker.Check(markdown.Link{URL: test.url})
+		if result.StatusCode == http.StatusOK && result.Redirect == test.redirect {
+			t.Logf("\t%s\tTest %d:\tShould be redirected to %q.", success, testID, test.redirect)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould be redirected to %q : %+v", failure, testID, test.redirect, result)
+		}
+		if result.Permanent == test.permanent {
+			t.Logf("\t%s\tTest %d:\tShould be permanently redirected: %v.", success, testID, test.permanent)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould be permanently redirected: %v : %+v", failure, testID, test.permanent, result)
+		}
+	}
+}
+
+func TestURLCheckCancel(t *testing.T) {
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	defer close(release)
+
+	checker := NewURLCheck(&http.Client{})
+	links := []markdown.Link{{URL: srv.URL + "/fast"}, {URL: srv.URL + "/slow"}}
+
+	t.Log("Given a check that runs past its deadline")
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		results, err := checker.CheckBatchContext(ctx, links)
+		if err == context.DeadlineExceeded {
+			t.Logf("\t%s\tShould report that the deadline passed.", success)
+		} else {
+			t.Errorf("\t%s\tShould report that the deadline passed : %v", failure, err)
+		}
+		if len(results) == 1 && results[0].URL == srv.URL+"/fast" {
+			t.Logf("\t%s\tShould keep the outcome of the links that were checked.", success)
+		} else {
+			t.Errorf("\t%s\tShould keep the outcome of the links that were checked : %v", failure, results)
+		}
+		if _, ok := checker.checked[srv.URL+"/slow"]; !ok {
+			t.Logf("\t%s\tShould forget the link that was cut short.", success)
+		} else {
+			t.Errorf("\t%s\tShould forget the link that was cut short.", failure)
+		}
+	}
+}
+
+func TestURLCheckStream(t *testing.T) {
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	checker := NewURLCheck(&http.Client{})
+	links := make(chan markdown.Link)
+	results := checker.Stream(context.Background(), links)
+
+	t.Log("Given links that arrive while others are still being checked")
+	{
+		links <- markdown.Link{URL: srv.URL + "/slow"}
+		links <- markdown.Link{URL: srv.URL + "/fast"}
+
+		select {
+		case result := <-results:
+			if result.URL == srv.URL+"/fast" {
+				t.Logf("\t%s\tShould send a result as soon as its link has been checked.", success)
+			} else {
+				t.Errorf("\t%s\tShould send a result as soon as its link has been checked : %v", failure, result)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("\t%s\tShould send a result as soon as its link has been checked.", failure)
+		}
+
+		close(release)
+		close(links)
+		var rest []Result
+		for result := range results {
+			rest = append(rest, result)
+		}
+		if len(rest) == 1 && rest[0].URL == srv.URL+"/slow" {
+			t.Logf("\t%s\tShould close the results once every link has been checked.", success)
+		} else {
+			t.Errorf("\t%s\tShould close the results once every link has been checked : %v", failure, rest)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	results := []Result{
+		{Link: markdown.Link{URL: "https://example.com/b", File: "README.md", Line: 4}},
+		{Link: markdown.Link{URL: "https://example.com/a", File: "README.md", Line: 4}},
+		{Link: markdown.Link{URL: "https://example.com/c", File: "README.md", Line: 2}},
+	}
+	want := []string{"https://example.com/c", "https://example.com/a", "https://example.com/b"}
+
+	t.Log("Given the need to report the results in the same order on every run")
+	Sort(results)
+	for testID, url := range want {
+		t.Logf("Test %d:\tWhen sorting %s", testID, url)
+		if results[testID].URL == url {
+			t.Logf("\t%s\tTest %d:\tShould be ordered by file, line and then URL.", success, testID)
+		} else {
+			t.Errorf("\t%s\tTest %d:\tShould be ordered by file, line and then URL : %+v", failure, testID, results[testID])
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package wiki clones the wiki of a GitHub repository and checks the links
+// of its pages, including the [[Page Name]] links between pages.
+package wiki
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// Remote returns the git remote of the wiki of a repository hosted on the
+// website, e.g., https://github.com.
+func Remote(website string, owner string, reponame string) string {
+	return fmt.Sprintf("%s/%s/%s.wiki.git", strings.TrimSuffix(website, "/"), owner, reponame)
+}
+
+// Clone makes a shallow clone of the wiki at the remote into dir. When a
+// token is given it is passed to git through the environment, rather than
+// within the remote or arguments where it could be seen by other processes.
+func Clone(ctx context.Context, remote string, token string, dir string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1", remote, dir)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if token != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials,
+		)
+	}
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git clone %s: %v: %s", remote, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Wiki is a local copy of the wiki of a repository.
+type Wiki struct {
+	// Dir is the directory holding the pages of the wiki.
+	Dir string
+	// URL is the address of the wiki on the website, e.g.,
+	// https://github.com/jwhitt3r/m-check/wiki.
+	URL string
+	// Repository is recorded against every link found within the wiki,
+	// e.g., jwhitt3r/m-check.wiki.
+	Repository string
+	// pages holds the normalised name of every page.
+	pages map[string]bool
+}
+
+// Open indexes the pages of the wiki found within dir.
+func Open(dir string, url string, repository string) (*Wiki, error) {
+	files, err := directory.MarkdownFiles(dir, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := Wiki{Dir: dir, URL: strings.TrimSuffix(url, "/"), Repository: repository, pages: make(map[string]bool)}
+	for _, file := range files {
+		name := filepath.Base(file)
+		w.pages[normalise(strings.TrimSuffix(name, filepath.Ext(name)))] = true
+	}
+	return &w, nil
+}
+
+// normalise turns the name of a page into the form its file is named in,
+// where spaces become hyphens, ignoring case as GitHub does.
+func normalise(page string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(page), " ", "-"))
+}
+
+// pageLink matches a link between the pages of a wiki, in the form
+// [[Page Name]] or [[Link Text|Page Name]].
+var pageLink = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// Extract returns the links of every page of the wiki, which are to be
+// checked as any other link is, along with the results of the links between
+// pages, which are resolved against the pages of the wiki. Paths are
+// relative to the directory of the wiki.
+func (w *Wiki) Extract() ([]markdown.Link, []urlcheck.Result, error) {
+	files, err := directory.MarkdownFiles(w.Dir, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var links []markdown.Link
+	var results []urlcheck.Result
+	for _, file := range files {
+		rel, err := filepath.Rel(w.Dir, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		rel = filepath.ToSlash(rel)
+
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, link := range markdown.Extract(f, rel) {
+			link.Repository = w.Repository
+			links = append(links, link)
+		}
+		if _, err := f.Seek(0, 0); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		results = append(results, w.resolve(f, rel)...)
+		f.Close()
+	}
+	return links, results, nil
+}
+
+// resolve checks every link between pages found within the file.
+func (w *Wiki) resolve(f *os.File, rel string) []urlcheck.Result {
+	var results []urlcheck.Result
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		for _, match := range pageLink.FindAllStringSubmatch(scanner.Text(), -1) {
+			target := match[1]
+			if i := strings.LastIndex(target, "|"); i >= 0 {
+				target = target[i+1:]
+			}
+			// Links to other websites are checked as any other link.
+			if strings.Contains(target, "://") {
+				continue
+			}
+			if i := strings.Index(target, "#"); i >= 0 {
+				target = target[:i]
+			}
+			target = strings.TrimSpace(target)
+
+			result := urlcheck.Result{
+				Link:       markdown.Link{URL: w.URL + "/" + strings.ReplaceAll(target, " ", "-"), Repository: w.Repository, File: rel, Line: line},
+				StatusCode: http.StatusOK,
+			}
+			if !w.exists(target) {
+				result.StatusCode = http.StatusNotFound
+				result.Reason = fmt.Sprintf("wiki page %q does not exist", target)
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// exists reports whether the target of a link names a page of the wiki, or
+// a file, such as an image, held within it.
+func (w *Wiki) exists(target string) bool {
+	if w.pages[normalise(target)] {
+		return true
+	}
+	if filepath.Ext(target) == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(w.Dir, filepath.FromSlash(target)))
+	return err == nil
+}
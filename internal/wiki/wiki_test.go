@@ -0,0 +1,111 @@
+package wiki
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wiki")
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to create a directory : %v", failure, err)
+	}
+	defer os.RemoveAll(dir)
+
+	pages := map[string]string{
+		"Home.md": "Start with [[Getting Started]] or [[the install guide|Install Guide]].\n" +
+			"Then read [[Missing Page]] and see [[images/logo.png]].\n",
+		"Getting-Started.md":      "See [Jwhitt3rs Github](https://github.com/jwhitt3r).\n",
+		"guides/Install-Guide.md": "Back to [[home#top]].\n",
+		"images/logo.png":         "",
+	}
+	for name, content := range pages {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("\t%s\tShould be able to create %s : %v", failure, name, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("\t%s\tShould be able to create %s : %v", failure, name, err)
+		}
+	}
+
+	t.Log("Given the need to check the links of a wiki")
+	{
+		w, err := Open(dir, "https://github.com/jwhitt3r/m-check/wiki", "jwhitt3r/m-check.wiki")
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to open the wiki : %v", failure, err)
+		}
+		links, results, err := w.Extract()
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to extract the links of the wiki : %v", failure, err)
+		}
+
+		if len(links) == 1 && links[0].URL == "https://github.com/jwhitt3r" && links[0].File == "Getting-Started.md" && links[0].Repository == "jwhitt3r/m-check.wiki" {
+			t.Logf("\t%s\tShould find the links to other websites.", success)
+		} else {
+			t.Errorf("\t%s\tShould find the links to other websites : %+v", failure, links)
+		}
+
+		var got []string
+		for _, result := range results {
+			got = append(got, fmt.Sprintf("%s:%d %s %d", result.File, result.Line, result.URL, result.StatusCode))
+		}
+		want := []string{
+			"Home.md:1 https://github.com/jwhitt3r/m-check/wiki/Getting-Started 200",
+			"Home.md:1 https://github.com/jwhitt3r/m-check/wiki/Install-Guide 200",
+			"Home.md:2 https://github.com/jwhitt3r/m-check/wiki/Missing-Page 404",
+			"Home.md:2 https://github.com/jwhitt3r/m-check/wiki/images/logo.png 200",
+			"guides/Install-Guide.md:1 https://github.com/jwhitt3r/m-check/wiki/home 200",
+		}
+		if fmt.Sprint(got) == fmt.Sprint(want) {
+			t.Logf("\t%s\tShould resolve the links between pages.", success)
+		} else {
+			t.Errorf("\t%s\tShould resolve the links between pages :\n%v\n%v", failure, got, want)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wiki")
+	if err != nil {
+		t.Fatalf("\t%s\tShould be able to create a directory : %v", failure, err)
+	}
+	defer os.RemoveAll(dir)
+
+	remote := filepath.Join(dir, "m-check.wiki")
+	for _, args := range [][]string{
+		{"init", "--quiet", remote},
+		{"-C", remote, "-c", "user.name=m-check", "-c", "user.email=m-check@example.com", "commit", "--quiet", "--allow-empty", "-m", "Initial Home page"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("\t%s\tShould be able to create a wiki to clone : %v: %s", failure, err, out)
+		}
+	}
+
+	t.Log("Given the need to fetch the wiki of a repository")
+	{
+		clone := filepath.Join(dir, "clone")
+		if err := Clone(context.Background(), "file://"+remote, "12345", clone); err != nil {
+			t.Fatalf("\t%s\tShould be able to clone the wiki : %v", failure, err)
+		}
+		if _, err := os.Stat(filepath.Join(clone, ".git")); err == nil {
+			t.Logf("\t%s\tShould clone the wiki into the directory.", success)
+		} else {
+			t.Errorf("\t%s\tShould clone the wiki into the directory : %v", failure, err)
+		}
+
+		if err := Clone(context.Background(), "file://"+filepath.Join(dir, "missing.wiki"), "", filepath.Join(dir, "missing")); err != nil {
+			t.Logf("\t%s\tShould fail to clone a wiki that does not exist : %v", success, err)
+		} else {
+			t.Errorf("\t%s\tShould fail to clone a wiki that does not exist.", failure)
+		}
+	}
+}
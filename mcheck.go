@@ -0,0 +1,478 @@
+// Package mcheck checks the links found within markdown documentation, so
+// that m-check can be embedded within other documentation tooling.
+//
+// A check is made up of three stages, each of which is an interface so that
+// it can be replaced: an Extractor gathers the links, a Checker checks each
+// of them, and any number of Reporters are given the resulting Report. Run
+// ties the stages together, returning errors rather than exiting the
+// process:
+//
+//	rep, err := mcheck.Run(ctx, mcheck.Options{
+//		Extractor: &mcheck.Directory{Root: "./docs"},
+//		Reporters: []mcheck.Reporter{&mcheck.WriterReporter{W: os.Stdout, Format: "markdown"}},
+//	})
+package mcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jwhitt3r/m-check/internal/markdown"
+	"github.com/jwhitt3r/m-check/internal/platform/directory"
+	"github.com/jwhitt3r/m-check/internal/repo"
+	"github.com/jwhitt3r/m-check/internal/report"
+	"github.com/jwhitt3r/m-check/internal/source"
+	"github.com/jwhitt3r/m-check/internal/urlcheck"
+)
+
+// Link is a URL found within markdown documentation and where it was found.
+type Link = markdown.Link
+
+// Result is the outcome of checking a single Link.
+type Result = urlcheck.Result
+
+// Report holds the Results of a check, along with what could not be
+// checked.
+type Report = report.Report
+
+// Formats lists the names of every format a Report can be written in.
+var Formats = report.Formats
+
+// Extractor gathers the links to be checked.
+type Extractor interface {
+	// Extract returns the links to be checked. An Extractor that could only
+	// gather some of the links returns them along with an *IncompleteError.
+	Extract(ctx context.Context) ([]Link, error)
+}
+
+// Checker checks links.
+type Checker interface {
+	// Check returns the Result of each link.
+	Check(ctx context.Context, links []Link) ([]Result, error)
+}
+
+// Reporter is given the Report of a check, e.g., to write it out or publish
+// it elsewhere.
+type Reporter interface {
+	Report(ctx context.Context, rep *Report) error
+}
+
+// StreamExtractor is an Extractor that can send each link as soon as it has
+// been found, which Run prefers so that links are checked while the rest
+// are still being extracted.
+type StreamExtractor interface {
+	Extractor
+	// ExtractStream sends each link to out, which it leaves open, returning
+	// an *IncompleteError when only some of the links could be gathered.
+	ExtractStream(ctx context.Context, out chan<- Link) error
+}
+
+// StreamChecker is a Checker that can check each link as soon as it
+// arrives, which Run prefers over checking the links in a single batch.
+type StreamChecker interface {
+	Checker
+	// CheckStream checks each link received from links, sending each Result
+	// as soon as it is known. The returned channel is closed once links has
+	// been closed and every check has completed.
+	CheckStream(ctx context.Context, links <-chan Link) <-chan Result
+}
+
+// ResultReporter is a Reporter that is also given each Result as soon as it
+// is known, before the Report of the whole check.
+type ResultReporter interface {
+	Reporter
+	Result(ctx context.Context, result Result) error
+}
+
+// ExtractorFunc adapts a function into an Extractor.
+type ExtractorFunc func(ctx context.Context) ([]Link, error)
+
+// Extract calls f(ctx).
+func (f ExtractorFunc) Extract(ctx context.Context) ([]Link, error) {
+	return f(ctx)
+}
+
+// CheckerFunc adapts a function into a Checker.
+type CheckerFunc func(ctx context.Context, links []Link) ([]Result, error)
+
+// Check calls f(ctx, links).
+func (f CheckerFunc) Check(ctx context.Context, links []Link) ([]Result, error) {
+	return f(ctx, links)
+}
+
+// ReporterFunc adapts a function into a Reporter.
+type ReporterFunc func(ctx context.Context, rep *Report) error
+
+// Report calls f(ctx, rep).
+func (f ReporterFunc) Report(ctx context.Context, rep *Report) error {
+	return f(ctx, rep)
+}
+
+// IncompleteError is returned by an Extractor alongside the links it could
+// gather, describing what could not be gathered.
+type IncompleteError struct {
+	// Errors holds each failure.
+	Errors []error
+}
+
+func (e *IncompleteError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("incomplete: %s", strings.Join(msgs, "; "))
+}
+
+// Options configures Run.
+type Options struct {
+	// Extractor gathers the links to be checked, and must be set.
+	Extractor Extractor
+	// Checker checks the links, by default an HTTPChecker with a five second
+	// timeout.
+	Checker Checker
+	// Reporters are each given the Report, in order.
+	Reporters []Reporter
+}
+
+// Run extracts, checks and reports the links described by the Options,
+// returning the Report. The stages run as a pipeline, so that links are
+// checked as soon as they have been extracted and each Result is given to
+// the ResultReporters as soon as it is known. What the Extractor could not
+// gather is recorded within the Errors of the Report, rather than stopping
+// the check.
+//
+// When the context is cancelled, or its deadline passes, no further links
+// are checked. The Report of the links checked so far is still given to
+// the Reporters, and is returned along with the error of the context.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Extractor == nil {
+		return nil, errors.New("mcheck: no Extractor has been set")
+	}
+	checker := opts.Checker
+	if checker == nil {
+		checker = NewHTTPChecker(&http.Client{Timeout: 5 * time.Second})
+	}
+
+	links := make(chan Link, urlcheck.Workers)
+	var extractErr error
+	go func() {
+		extractErr = extract(ctx, opts.Extractor, links)
+		close(links)
+	}()
+	var checkErr error
+	results := check(ctx, checker, links, &checkErr)
+
+	rep := report.New(nil)
+	var reportErr error
+	for result := range results {
+		rep.Results = append(rep.Results, result)
+		for _, reporter := range opts.Reporters {
+			r, ok := reporter.(ResultReporter)
+			if !ok || reportErr != nil {
+				continue
+			}
+			reportErr = r.Result(ctx, result)
+		}
+	}
+	if reportErr != nil {
+		return nil, fmt.Errorf("reporting results: %w", reportErr)
+	}
+	urlcheck.Sort(rep.Results)
+
+	if extractErr != nil {
+		var incomplete *IncompleteError
+		switch {
+		case errors.As(extractErr, &incomplete):
+			for _, err := range incomplete.Errors {
+				rep.Errors = append(rep.Errors, err.Error())
+			}
+		case ctx.Err() == nil:
+			return nil, fmt.Errorf("extracting links: %w", extractErr)
+		}
+	}
+	if checkErr != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("checking links: %w", checkErr)
+	}
+	if err := ctx.Err(); err != nil {
+		rep.Errors = append(rep.Errors, fmt.Sprintf("stopped early: %v, only the %d links checked so far are reported", err, len(rep.Results)))
+	}
+
+	for _, reporter := range opts.Reporters {
+		if err := reporter.Report(ctx, rep); err != nil {
+			return nil, fmt.Errorf("reporting results: %w", err)
+		}
+	}
+	return rep, ctx.Err()
+}
+
+// extract sends the links of the Extractor to out, as each is found when the
+// Extractor is a StreamExtractor, or otherwise once every link is found.
+func extract(ctx context.Context, extractor Extractor, out chan<- Link) error {
+	if s, ok := extractor.(StreamExtractor); ok {
+		return s.ExtractStream(ctx, out)
+	}
+
+	links, err := extractor.Extract(ctx)
+	for _, link := range links {
+		select {
+		case out <- link:
+		case <-ctx.Done():
+			return err
+		}
+	}
+	return err
+}
+
+// check checks the links received from links, as each arrives when the
+// Checker is a StreamChecker, or otherwise in a single batch once links has
+// been closed, in which case an error is set within err.
+func check(ctx context.Context, checker Checker, links <-chan Link, err *error) <-chan Result {
+	if s, ok := checker.(StreamChecker); ok {
+		return s.CheckStream(ctx, links)
+	}
+
+	results := make(chan Result)
+	go func() {
+		defer close(results)
+		var batch []Link
+		for link := range links {
+			batch = append(batch, link)
+		}
+		var checked []Result
+		checked, *err = checker.Check(ctx, batch)
+		for _, result := range checked {
+			results <- result
+		}
+	}()
+	return results
+}
+
+// Directory extracts the links of the markdown files within a local
+// directory, skipping the files excluded by a .gitignore file.
+type Directory struct {
+	// Root is the directory to search, by default the current directory.
+	Root string
+	// Include holds the globs of the files to include, by default "*.md"
+	// and "*.markdown".
+	Include []string
+	// Exclude holds the globs of the files or directories to exclude.
+	Exclude []string
+}
+
+// Extract returns the links of every markdown file within the directory.
+func (d *Directory) Extract(ctx context.Context) ([]Link, error) {
+	files, err := d.files(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	links, errs := markdown.ExtractBatch(files)
+	if len(errs) > 0 {
+		return links, &IncompleteError{Errors: errs}
+	}
+	return links, nil
+}
+
+// ExtractStream sends the links of each markdown file within the directory
+// to out as soon as the file has been read.
+func (d *Directory) ExtractStream(ctx context.Context, out chan<- Link) error {
+	files, err := d.files(ctx)
+	if err != nil {
+		return err
+	}
+
+	if errs := markdown.ExtractStream(ctx, files, out); len(errs) > 0 {
+		return &IncompleteError{Errors: errs}
+	}
+	return nil
+}
+
+// files returns the path of every markdown file within the directory.
+func (d *Directory) files(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	root := d.Root
+	if root == "" {
+		root = "."
+	}
+	return directory.MarkdownFiles(root, d.Include, d.Exclude)
+}
+
+// Remote extracts the links of the markdown files of a repository hosted on
+// GitHub, GitLab, Gitea or Bitbucket, without saving them to disk.
+type Remote struct {
+	// Provider is the hosting service, one of github, gitlab, gitea or
+	// bitbucket. By default github.
+	Provider string
+	// BaseURL is the base URL of the API of a self-hosted instance.
+	BaseURL string
+	// Token authenticates with the hosting service.
+	Token string
+	// Owner is the owner of the repository.
+	Owner string
+	// Repo is the name of the repository.
+	Repo string
+	// Path is the directory or markdown file to extract, by default the
+	// whole repository.
+	Path string
+	// Ref is the branch, tag or commit to extract, by default the default
+	// branch.
+	Ref string
+
+	// The following only apply to GitHub.
+
+	// WaitForRateLimit is the longest to wait for the rate limit of the
+	// GitHub API to reset once it has been exhausted, where zero fails
+	// straight away.
+	WaitForRateLimit time.Duration
+	// AppID is the ID of a GitHub App to authenticate as an installation
+	// of, in place of the token.
+	AppID int64
+	// AppPrivateKey holds the PEM encoded private key of the GitHub App.
+	AppPrivateKey []byte
+	// AppInstallation is the ID of the installation of the GitHub App, by
+	// default the installation on the repository.
+	AppInstallation int64
+	// APICache is a file to remember the responses of the GitHub API in
+	// between runs, so that unchanged responses do not count against the
+	// rate limit.
+	APICache string
+}
+
+// Extract returns the links of every markdown file within the path of the
+// repository.
+func (r *Remote) Extract(ctx context.Context) ([]Link, error) {
+	p, responses, err := r.provider()
+	if err != nil {
+		return nil, err
+	}
+	links, err := source.Extract(ctx, p, r.Path, r.Ref)
+	return links, save(responses, incomplete(err))
+}
+
+// ExtractStream sends the links of each markdown file within the path of
+// the repository to out as soon as the file has been fetched.
+func (r *Remote) ExtractStream(ctx context.Context, out chan<- Link) error {
+	p, responses, err := r.provider()
+	if err != nil {
+		return err
+	}
+	return save(responses, incomplete(source.Stream(ctx, p, r.Path, r.Ref, out)))
+}
+
+// provider connects to the hosting service of the repository, returning the
+// cache of API responses when one has been asked for.
+func (r *Remote) provider() (source.Provider, *repo.ResponseCache, error) {
+	opts := source.Options{
+		Provider: r.Provider,
+		BaseURL:  r.BaseURL,
+		Token:    r.Token,
+		Owner:    r.Owner,
+		Repo:     r.Repo,
+		MaxWait:  r.WaitForRateLimit,
+	}
+	if r.AppID != 0 {
+		key, err := repo.ParsePrivateKey(r.AppPrivateKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.App = &repo.App{ID: r.AppID, PrivateKey: key, InstallationID: r.AppInstallation}
+	}
+	if r.APICache != "" {
+		c, err := repo.OpenResponseCache(r.APICache)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Responses = c
+	}
+	p, err := source.New(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, opts.Responses, nil
+}
+
+// save writes the cache of API responses back to disk, when there is one,
+// returning err, or the failure to save the cache when err is nil.
+func save(responses *repo.ResponseCache, err error) error {
+	if responses == nil {
+		return err
+	}
+	if saveErr := responses.Save(); saveErr != nil && err == nil {
+		return fmt.Errorf("saving API cache: %w", saveErr)
+	}
+	return err
+}
+
+// incomplete turns the error describing the files of a repository that
+// could not be fetched into an *IncompleteError.
+func incomplete(err error) error {
+	var partial *source.PartialError
+	if errors.As(err, &partial) {
+		return &IncompleteError{Errors: partial.Errors}
+	}
+	return err
+}
+
+// HTTPChecker checks each link with a GET request, only requesting a URL
+// found many times once.
+type HTTPChecker struct {
+	checker *urlcheck.URLChecker
+}
+
+// NewHTTPChecker creates an HTTPChecker making requests with the client.
+func NewHTTPChecker(client *http.Client) *HTTPChecker {
+	return &HTTPChecker{checker: urlcheck.NewURLCheck(client)}
+}
+
+// Check requests every link concurrently. Once the context is cancelled no
+// further requests are made, and the Results of the links checked so far
+// are returned along with the error of the context.
+func (c *HTTPChecker) Check(ctx context.Context, links []Link) ([]Result, error) {
+	return c.checker.CheckBatchContext(ctx, links)
+}
+
+// CheckStream requests each link as soon as it arrives.
+func (c *HTTPChecker) CheckStream(ctx context.Context, links <-chan Link) <-chan Result {
+	return c.checker.Stream(ctx, links)
+}
+
+// WriterReporter writes the Report out in one of the Formats.
+type WriterReporter struct {
+	// W is where the Report is written.
+	W io.Writer
+	// Format is one of the Formats, by default text.
+	Format string
+	// Stream writes each Result as soon as it is known, rather than once
+	// every link has been checked, which is only supported by the text
+	// format. Results are then written in the order their checks complete.
+	Stream bool
+}
+
+// streaming reports whether each Result is written as soon as it is known.
+func (w *WriterReporter) streaming() bool {
+	return w.Stream && (w.Format == "text" || w.Format == "")
+}
+
+// Result writes the Result to W when streaming.
+func (w *WriterReporter) Result(ctx context.Context, result Result) error {
+	if !w.streaming() {
+		return nil
+	}
+	return report.NewTextStream(w.W).Result(result)
+}
+
+// Report writes the Report to W, or only what follows its Results when
+// they have already been streamed.
+func (w *WriterReporter) Report(ctx context.Context, rep *Report) error {
+	if w.streaming() {
+		return report.NewTextStream(w.W).Close(rep)
+	}
+	return rep.Write(w.W, w.Format)
+}
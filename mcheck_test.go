@@ -0,0 +1,158 @@
+package mcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const success = "\u2713"
+const failure = "\u2717"
+
+func TestRun(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	content := "[ok](" + srv.URL + "/ok)\n[missing](" + srv.URL + "/missing)\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("\t%s\tShould be able to write the documentation : %v", failure, err)
+	}
+
+	t.Log("Given the need to embed m-check within other tooling")
+	{
+		var buf bytes.Buffer
+		rep, err := Run(context.Background(), Options{
+			Extractor: &Directory{Root: dir},
+			Checker:   NewHTTPChecker(&http.Client{Timeout: time.Second}),
+			Reporters: []Reporter{&WriterReporter{W: &buf, Format: "text"}},
+		})
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to run a check : %v", failure, err)
+		}
+		t.Logf("\t%s\tShould be able to run a check.", success)
+
+		if len(rep.Results) == 2 && len(rep.Broken()) == 1 {
+			t.Logf("\t%s\tShould find 1 of 2 links broken.", success)
+		} else {
+			t.Errorf("\t%s\tShould find 1 of 2 links broken : %v", failure, rep.Results)
+		}
+		if strings.Contains(buf.String(), srv.URL+"/missing - 404") {
+			t.Logf("\t%s\tShould write the report.", success)
+		} else {
+			t.Errorf("\t%s\tShould write the report : %q", failure, buf.String())
+		}
+	}
+
+	t.Log("Given an Extractor that fails")
+	{
+		extractor := ExtractorFunc(func(ctx context.Context) ([]Link, error) {
+			return nil, errors.New("no documentation")
+		})
+		if _, err := Run(context.Background(), Options{Extractor: extractor}); err != nil {
+			t.Logf("\t%s\tShould return the error rather than exiting : %v", success, err)
+		} else {
+			t.Errorf("\t%s\tShould return the error rather than exiting.", failure)
+		}
+
+		incomplete := ExtractorFunc(func(ctx context.Context) ([]Link, error) {
+			return nil, &IncompleteError{Errors: []error{errors.New("docs/a.md: 500 Internal Server Error")}}
+		})
+		rep, err := Run(context.Background(), Options{Extractor: incomplete, Checker: CheckerFunc(func(ctx context.Context, links []Link) ([]Result, error) {
+			return nil, nil
+		})})
+		if err == nil && len(rep.Errors) == 1 {
+			t.Logf("\t%s\tShould record what could not be extracted within the report.", success)
+		} else {
+			t.Errorf("\t%s\tShould record what could not be extracted within the report : %v", failure, err)
+		}
+	}
+}
+
+func TestRunStream(t *testing.T) {
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) { <-release })
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// The extractor only finds the slow link once the fast link has been
+	// checked, which can only happen when links are checked as soon as
+	// they are extracted.
+	checked := make(chan struct{})
+	extractor := streamExtractor(func(ctx context.Context, out chan<- Link) error {
+		out <- Link{URL: srv.URL + "/fast"}
+		select {
+		case <-checked:
+		case <-time.After(5 * time.Second):
+			return errors.New("the first link was not checked while extracting")
+		}
+		out <- Link{URL: srv.URL + "/slow"}
+		close(release)
+		return nil
+	})
+
+	var buf bytes.Buffer
+	writer := &WriterReporter{W: &buf, Format: "text", Stream: true}
+	var streamed []string
+	reporter := resultReporter{Reporter: ReporterFunc(func(ctx context.Context, rep *Report) error { return nil }), result: func(result Result) {
+		if len(streamed) == 0 {
+			close(checked)
+		}
+		streamed = append(streamed, result.URL)
+	}}
+
+	t.Log("Given the need to check links as soon as they are extracted")
+	{
+		rep, err := Run(context.Background(), Options{
+			Extractor: extractor,
+			Checker:   NewHTTPChecker(&http.Client{Timeout: 5 * time.Second}),
+			Reporters: []Reporter{reporter, writer},
+		})
+		if err != nil {
+			t.Fatalf("\t%s\tShould be able to run a check : %v", failure, err)
+		}
+		if len(rep.Errors) == 0 && len(streamed) == 2 && streamed[0] == srv.URL+"/fast" {
+			t.Logf("\t%s\tShould report each result as soon as it is checked.", success)
+		} else {
+			t.Errorf("\t%s\tShould report each result as soon as it is checked : %v %v", failure, streamed, rep.Errors)
+		}
+		if strings.HasPrefix(buf.String(), srv.URL+"/fast - 200\n") {
+			t.Logf("\t%s\tShould write each result as soon as it is checked.", success)
+		} else {
+			t.Errorf("\t%s\tShould write each result as soon as it is checked : %q", failure, buf.String())
+		}
+	}
+}
+
+// streamExtractor adapts a function into a StreamExtractor.
+type streamExtractor func(ctx context.Context, out chan<- Link) error
+
+func (f streamExtractor) Extract(ctx context.Context) ([]Link, error) {
+	return nil, errors.New("links should be streamed")
+}
+
+func (f streamExtractor) ExtractStream(ctx context.Context, out chan<- Link) error {
+	return f(ctx, out)
+}
+
+// resultReporter is a ResultReporter calling result with each Result.
+type resultReporter struct {
+	Reporter
+	result func(result Result)
+}
+
+func (r resultReporter) Result(ctx context.Context, result Result) error {
+	r.result(result)
+	return nil
+}